@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/koba-e964/crypto-primality-proof/internal/cliutil"
+	"github.com/koba-e964/crypto-primality-proof/primality"
+)
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "output the difference as JSON")
+	var targets cliutil.BigIntList
+	flag.Var(&targets, "target", "report dependency closure changes for this number (can be repeated; default: numbers nothing depends on)")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) != 2 {
+		panic("usage: diff [flags] OLD.json NEW.json")
+	}
+	from, _, err := primality.LoadRegistry(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	to, _, err := primality.LoadRegistry(args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	diff := primality.Diff(from, to, targets)
+	if *jsonOutput {
+		jsonString, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(jsonString))
+	} else {
+		fmt.Print(diff.String())
+	}
+	if !diff.Empty() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/koba-e964/crypto-primality-proof/internal/cliutil"
+	"github.com/koba-e964/crypto-primality-proof/primality"
+)
+
+func main() {
+	output := flag.String("o", "", "output file (default: standard output)")
+	var targets cliutil.BigIntList
+	flag.Var(&targets, "target", "only output the proofs needed to prove this number, e.g. 2^255-19 (can be repeated)")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		panic("no arguments")
+	}
+	regs := []*primality.Registry{}
+	for _, filename := range args {
+		reg, _, err := primality.LoadRegistry(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+		regs = append(regs, reg)
+	}
+	merged, err := primality.Merge(regs...)
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to merge: %w", err))
+	}
+	if len(targets) > 0 {
+		merged, err = merged.Closure(targets)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	jsonString, err := merged.MarshalCanonical()
+	if err != nil {
+		panic(err)
+	}
+	if *output == "" {
+		os.Stdout.Write(jsonString)
+		return
+	}
+	if err := os.WriteFile(*output, jsonString, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("wrote", *output)
+}
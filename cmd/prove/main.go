@@ -1,48 +1,152 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log"
 	"math/big"
 	"os"
-	"slices"
+	"os/signal"
+	"runtime"
 
+	"github.com/koba-e964/crypto-primality-proof/internal/cliutil"
 	"github.com/koba-e964/crypto-primality-proof/primality"
 )
 
 func main() {
-	argv := os.Args
-	if len(argv) < 2 {
+	workers := flag.Int("workers", runtime.NumCPU(), "number of primes proven concurrently")
+	var knownPaths cliutil.StringList
+	flag.Var(&knownPaths, "known", "registry file, directory or glob pattern whose proven numbers are reused instead of proven again (can be repeated)")
+	trustKnownAxioms := flag.Bool("trust-known-axioms", false, "also reuse the axioms of -known registries instead of proving their numbers")
+	objective := flag.String("objective", "", "what to minimize when choosing among the proofs of each prime: bytes, deps or time (empty: default choice)")
+	checkpointPath := flag.String("checkpoint", "", "file recording completed proofs and the prime factors of N-1 found, resumed from if it exists")
+	progress := flag.Bool("progress", false, "log progress to stderr")
+	hintsFile := flag.String("hints", "", "JSON file of known primes and factorizations tried before factoring")
+	pm1B1 := flag.Uint64("pm1-b1", 0, "stage 1 bound of the p-1 method (0: default)")
+	pm1B2 := flag.Uint64("pm1-b2", 0, "stage 2 bound of the p-1 method (0: 100 times the stage 1 bound)")
+	pp1B1 := flag.Uint64("pp1-b1", 0, "stage 1 bound of the p+1 method (0: default)")
+	pp1B2 := flag.Uint64("pp1-b2", 0, "stage 2 bound of the p+1 method (0: 100 times the stage 1 bound)")
+	pp1Starts := flag.Int("pp1-starts", 0, "number of starting values the p+1 method tries (0: default)")
+	siqsMaxDigits := flag.Int("siqs-max-digits", 0, "maximum number of digits of a composite factor SIQS is used on (0: default, negative: never)")
+	ecmB1 := flag.Uint64("ecm-b1", 0, "stage 1 bound of ECM (0: default)")
+	ecmB2 := flag.Uint64("ecm-b2", 0, "stage 2 bound of ECM (0: 100 times the stage 1 bound)")
+	ecmCurves := flag.Int("ecm-curves", 0, "number of curves ECM tries (0: default)")
+	ecmSeed := flag.Uint64("ecm-seed", 0, "seed of the curves ECM tries")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) < 1 {
 		panic("missing argument: integer")
 	}
-	nString := argv[1]
-	n := big.NewInt(0)
-	if _, ok := n.SetString(nString, 10); !ok {
+	nString := args[0]
+	n, err := cliutil.ParseInt(nString)
+	if err != nil {
 		panic("invalid integer: " + nString)
 	}
-	seen := map[string]struct{}{}
-	stack := []*big.Int{n}
-	registry := primality.Registry{}
-	for len(stack) > 0 {
-		n := stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
-		nString := n.String()
-		if _, ok := seen[nString]; ok {
-			continue
+	known := []*primality.Registry{}
+	for _, path := range knownPaths {
+		reg, _, err := primality.LoadRegistry(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		known = append(known, reg)
+	}
+	var hints []*big.Int
+	if *hintsFile != "" {
+		hints, err = cliutil.ReadHintsFile(*hintsFile)
+		if err != nil {
+			log.Fatal(err)
 		}
-		seen[nString] = struct{}{}
-		proof, err := primality.Prove(n)
+	}
+	var checkpoint *primality.Checkpoint
+	if *checkpointPath != "" {
+		checkpoint, err = primality.OpenCheckpoint(*checkpointPath)
 		if err != nil {
-			panic(err)
+			log.Fatal(err)
+		}
+		if checkpoint.Len() > 0 {
+			log.Printf("resuming from %s with %d proofs", *checkpointPath, checkpoint.Len())
 		}
-		registry.Proofs = append(registry.Proofs, *proof)
-		dep := proof.Dep()
-		stack = append(stack, dep...)
 	}
-	slices.Reverse(registry.Proofs)
-	jsonString, err := json.MarshalIndent(registry, "", "  ")
+	var progressFunc func(primality.Progress)
+	if *progress {
+		progressFunc = logProgress
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	registry, err := primality.ProveRegistryContext(ctx, n, &primality.ProveOptions{
+		Workers:          *workers,
+		Known:            known,
+		TrustKnownAxioms: *trustKnownAxioms,
+		Objective:        primality.Objective(*objective),
+		Progress:         progressFunc,
+		Checkpoint:       checkpoint,
+		Factor: &primality.FactorOptions{
+			Hints: hints,
+			PMinus1: &primality.PMinus1Options{
+				B1: *pm1B1,
+				B2: *pm1B2,
+			},
+			PPlus1: &primality.PPlus1Options{
+				B1:     *pp1B1,
+				B2:     *pp1B2,
+				Starts: *pp1Starts,
+			},
+			SIQS: &primality.SIQSOptions{
+				MaxDigits: *siqsMaxDigits,
+			},
+			ECM: &primality.ECMOptions{
+				B1:     *ecmB1,
+				B2:     *ecmB2,
+				Curves: *ecmCurves,
+				Seed:   *ecmSeed,
+			},
+		},
+	})
+	if errors.Is(err, context.Canceled) && checkpoint != nil {
+		log.Fatalf("interrupted; run again with -checkpoint %s to resume", *checkpointPath)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, axiom := range registry.Axioms {
+		log.Printf("the proof relies on the axiom %s: %s", abbreviate((*big.Int)(axiom.N)), axiom.Provenance)
+	}
+	jsonString, err := registry.MarshalCanonical()
 	if err != nil {
 		panic(err)
 	}
-	fmt.Println(string(jsonString))
+	fmt.Print(string(jsonString))
+}
+
+func logProgress(p primality.Progress) {
+	prefix := fmt.Sprintf("[%d done, %d pending] %s", p.Done, p.Pending, abbreviate(p.N))
+	switch p.Stage {
+	case primality.StageFactoring:
+		if p.Factor.Composite != nil {
+			log.Printf("%s: factoring N-1: trying %s on a %d-digit composite", prefix, p.Factor.Method, len(p.Factor.Composite.String()))
+		} else if p.Factor.Remaining.Cmp(big.NewInt(1)) == 0 {
+			log.Printf("%s: factoring N-1: done with %d primes", prefix, len(p.Factor.Primes))
+		} else {
+			log.Printf("%s: factoring N-1: %d primes found, %d digits left", prefix, len(p.Factor.Primes), len(p.Factor.Remaining.String()))
+		}
+	case primality.StageProving:
+		log.Printf("%s: proving", prefix)
+	case primality.StageProved:
+		if p.Resumed {
+			log.Printf("%s: proved (from checkpoint)", prefix)
+		} else {
+			log.Printf("%s: proved", prefix)
+		}
+	}
+}
+
+// abbreviate returns n in decimal, with the middle digits elided if it is long.
+func abbreviate(n *big.Int) string {
+	s := n.String()
+	if len(s) <= 20 {
+		return s
+	}
+	return fmt.Sprintf("%s...%s (%d digits)", s[:8], s[len(s)-8:], len(s))
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/koba-e964/crypto-primality-proof/primality"
+)
+
+func main() {
+	keyPath := flag.String("key", "", "file containing the hex-encoded Ed25519 private key seed")
+	genkey := flag.Bool("genkey", false, "generate a new key pair, writing the private key to -key and the public key to -pub")
+	pubPath := flag.String("pub", "", "file to write the hex-encoded public key to with -genkey")
+	output := flag.String("o", "", "signature file (default: REGISTRY.sig)")
+	flag.Parse()
+	if *keyPath == "" {
+		panic("missing flag: -key")
+	}
+	if *genkey {
+		if *pubPath == "" {
+			panic("missing flag: -pub")
+		}
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(*keyPath, []byte(hex.EncodeToString(privateKey.Seed())+"\n"), 0o600); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*pubPath, []byte(hex.EncodeToString(publicKey)+"\n"), 0o644); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("wrote", *keyPath, "and", *pubPath)
+		return
+	}
+	args := flag.Args()
+	if len(args) != 1 {
+		panic("usage: sign -key KEY [-o SIGNATURE] REGISTRY")
+	}
+	keyString, err := os.ReadFile(*keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	privateKey, err := primality.ParsePrivateKey(string(keyString))
+	if err != nil {
+		log.Fatal(fmt.Errorf("invalid key in %s: %w", *keyPath, err))
+	}
+	reg, _, err := primality.LoadRegistry(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	// refuse to sign registries that are not valid
+	if err := reg.Check(); err != nil {
+		log.Fatal(fmt.Errorf("failed to verify %s: %w", args[0], err))
+	}
+	sig, err := primality.SignRegistry(reg, privateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	jsonString, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	jsonString = append(jsonString, '\n')
+	if *output == "" {
+		*output = args[0] + ".sig"
+	}
+	if err := os.WriteFile(*output, jsonString, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("wrote", *output)
+}
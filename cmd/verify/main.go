@@ -1,38 +1,104 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
+	"strings"
 
+	"github.com/koba-e964/crypto-primality-proof/internal/cliutil"
 	"github.com/koba-e964/crypto-primality-proof/primality"
 )
 
 func main() {
-	args := os.Args[1:]
+	lint := flag.Bool("lint", false, "report duplicate, orphan and out-of-order proofs")
+	strict := flag.Bool("strict", false, "fail if -lint reports any issue")
+	var targets cliutil.BigIntList
+	flag.Var(&targets, "target", "number the registry is meant to prove, used by -lint to find orphan proofs (can be repeated)")
+	cachePath := flag.String("cache", "", "file to remember verified proofs in, so that they are not verified again")
+	force := flag.Bool("force", false, "verify every proof again even if -cache says it was verified")
+	pubkeyPath := flag.String("pubkey", "", "file containing a hex-encoded Ed25519 public key; each argument must have a FILE.sig signature made with it")
+	flag.Parse()
+	args := flag.Args()
 	if len(args) == 0 {
 		panic("no arguments")
 	}
+	opts := &primality.CheckOptions{}
+	if *cachePath != "" {
+		cache, err := primality.OpenCache(*cachePath)
+		if err != nil {
+			log.Fatal(fmt.Errorf("failed to open cache %s: %w", *cachePath, err))
+		}
+		if *force {
+			cache.Reset()
+		}
+		opts.Cache = cache
+	}
+	var trusted ed25519.PublicKey
+	if *pubkeyPath != "" {
+		keyString, err := os.ReadFile(*pubkeyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if trusted, err = primality.ParsePublicKey(string(keyString)); err != nil {
+			log.Fatal(fmt.Errorf("invalid public key in %s: %w", *pubkeyPath, err))
+		}
+	}
 	failed := false
+	// each argument is a file, a directory or a glob pattern
 	for _, filename := range args {
-		dat, err := os.ReadFile(filename)
+		reg, origins, err := primality.LoadRegistry(filename)
 		if err != nil {
 			failed = true
-			log.Print(fmt.Errorf("failed to read %s: %w", filename, err))
+			log.Print(err)
 			continue
 		}
-		var reg primality.Registry
-		if err := json.Unmarshal(dat, &reg); err != nil {
-			failed = true
-			log.Print(fmt.Errorf("failed to unmarshal %s: %w", filename, err))
-			continue
-		}
-		if err := reg.Check(); err != nil {
+		if err := reg.CheckWithOptions(context.Background(), opts); err != nil {
 			failed = true
+			var depErr *primality.DependencyError
+			if errors.As(err, &depErr) {
+				files := origins.Proofs[depErr.Proof]
+				log.Print(fmt.Errorf("failed to verify %s: %w", strings.Join(files, ", "), err))
+				continue
+			}
 			log.Print(fmt.Errorf("failed to verify %s: %w", filename, err))
 			continue
 		}
+		if trusted != nil {
+			sig, err := primality.ReadSignatureFile(filename + ".sig")
+			if err != nil {
+				failed = true
+				log.Print(err)
+				continue
+			}
+			if err := primality.VerifyRegistrySignature(reg, sig, trusted); err != nil {
+				failed = true
+				log.Print(fmt.Errorf("failed to verify the signature of %s: %w", filename, err))
+				continue
+			}
+		}
+		for _, axiom := range reg.UsedAxioms() {
+			log.Printf("%s: assumes %s is prime: %s", filename, (*big.Int)(axiom.N).String(), axiom.Provenance)
+		}
+		if *lint {
+			issues := reg.Lint(targets)
+			for _, issue := range issues {
+				log.Printf("%s: %s", strings.Join(origins.Numbers[issue.N.String()], ", "), issue.String())
+			}
+			if *strict && len(issues) > 0 {
+				failed = true
+			}
+		}
+	}
+	if opts.Cache != nil {
+		if err := opts.Cache.Save(); err != nil {
+			log.Fatal(fmt.Errorf("failed to save cache %s: %w", *cachePath, err))
+		}
 	}
 	if failed {
 		os.Exit(1)
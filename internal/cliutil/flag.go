@@ -0,0 +1,40 @@
+package cliutil
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// BigIntList is a flag.Value that collects integers given by repeated flags.
+// Each integer is parsed by ParseInt.
+type BigIntList []*big.Int
+
+func (l *BigIntList) String() string {
+	strs := make([]string, len(*l))
+	for i, n := range *l {
+		strs[i] = n.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+func (l *BigIntList) Set(s string) error {
+	n, err := ParseInt(s)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, s)
+	}
+	*l = append(*l, n)
+	return nil
+}
+
+// StringList is a flag.Value that collects strings given by repeated flags.
+type StringList []string
+
+func (l *StringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *StringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
@@ -0,0 +1,62 @@
+package cliutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/koba-e964/crypto-primality-proof/primality"
+)
+
+// ReadHintsFile reads factorization hints from a JSON file.
+// The file is a list whose elements are either strings, each a number parsed by ParseInt,
+// or known factorizations in the format of primality.FactoredInt.
+// It returns the numbers and the primes of the factorizations, in order of appearance.
+// Factorizations are checked, but the primality of the numbers is not.
+func ReadHintsFile(name string) ([]*big.Int, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHints(data)
+}
+
+// ParseHints is like ReadHintsFile, but parses data instead of reading a file.
+func ParseHints(data []byte) ([]*big.Int, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	hints := []*big.Int{}
+	for i, entry := range entries {
+		var s string
+		if err := json.Unmarshal(entry, &s); err == nil {
+			n, err := ParseInt(s)
+			if err != nil {
+				return nil, fmt.Errorf("hint #%d: %w: %s", i, err, s)
+			}
+			hints = append(hints, n)
+			continue
+		}
+		var f primality.FactoredInt
+		if err := json.Unmarshal(entry, &f); err != nil {
+			return nil, fmt.Errorf("hint #%d: %w", i, err)
+		}
+		if f.Int == nil {
+			return nil, fmt.Errorf("hint #%d: missing int", i)
+		}
+		for _, entry := range f.Factorization {
+			if entry.Prime == nil {
+				return nil, fmt.Errorf("hint #%d: missing prime", i)
+			}
+		}
+		if err := f.Check(); err != nil {
+			return nil, fmt.Errorf("hint #%d: %w", i, err)
+		}
+		for _, entry := range f.Factorization {
+			hints = append(hints, (*big.Int)(entry.Prime))
+		}
+	}
+	return hints, nil
+}
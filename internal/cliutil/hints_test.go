@@ -0,0 +1,34 @@
+package cliutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHints(t *testing.T) {
+	data := []byte(`[
+		"181",
+		"2^127-1",
+		{"int": "12", "factorization": [{"prime": "2", "exponent": 2}, {"prime": "3", "exponent": 1}]}
+	]`)
+	hints, err := ParseHints(data)
+	assert.NoError(t, err)
+	m127 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	assert.Equal(t, []*big.Int{big.NewInt(181), m127, big.NewInt(2), big.NewInt(3)}, hints)
+}
+
+func TestParseHintsInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{}`,
+		`["2^"]`,
+		`[1]`,
+		`[{"factorization": [{"prime": "2", "exponent": 1}]}]`,
+		`[{"int": "2", "factorization": [{"exponent": 1}]}]`,
+		`[{"int": "12", "factorization": [{"prime": "2", "exponent": 1}, {"prime": "3", "exponent": 1}]}]`,
+	} {
+		_, err := ParseHints([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
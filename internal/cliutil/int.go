@@ -0,0 +1,28 @@
+package cliutil
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/koba-e964/crypto-primality-proof/internal/expr"
+)
+
+var ErrInvalidInt = errors.New("invalid integer")
+
+// ParseInt parses a non-negative integer written in decimal or as an expression like "2^255-19",
+// in the grammar of package expr. Expressions that are too large to evaluate (see expr.MaxBits) are rejected.
+func ParseInt(s string) (*big.Int, error) {
+	e, err := expr.Parse(s)
+	if errors.Is(err, expr.ErrTooLarge) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInt, err)
+	}
+	if err != nil {
+		return nil, ErrInvalidInt
+	}
+	value := e.Value()
+	if value.Sign() < 0 {
+		return nil, ErrInvalidInt
+	}
+	return value, nil
+}
@@ -0,0 +1,31 @@
+package cliutil
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInt(t *testing.T) {
+	p25519, _ := new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+	tests := []struct {
+		s        string
+		expected *big.Int
+	}{
+		{s: "181", expected: big.NewInt(181)},
+		{s: "2^255-19", expected: p25519},
+		{s: "2^255 - 19", expected: p25519},
+		{s: "3^2 * 5 + 1", expected: big.NewInt(46)},
+	}
+	for _, test := range tests {
+		actual, err := ParseInt(test.s)
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.expected, actual)
+		}
+	}
+	for _, s := range []string{"", "2^", "1-2", "12a", "-1", "2^99999999999"} {
+		_, err := ParseInt(s)
+		assert.ErrorIs(t, err, ErrInvalidInt, s)
+	}
+}
@@ -0,0 +1,177 @@
+// Package expr parses integers written as expressions like "2^255-19" or "2^4 * 3 * 5".
+package expr
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrSyntax   = errors.New("invalid expression")
+	ErrTooLarge = errors.New("expression too large")
+)
+
+// MaxBits bounds the size of the terms of an expression. Parse rejects an expression
+// with a term that may have more bits, so that evaluating it cannot exhaust time or memory.
+const MaxBits = 1 << 19
+
+// Grammar:
+// <expr> ::= <term> | <expr> "+" <term> | <expr> "-" <term>
+// <term> ::= <pow> | <term> "*" <pow>
+// <pow> ::= <num> | <num> "^" <num>
+// <num> = [0-9]+
+// Spaces are ignored. This grammar is LL(1) and can be parsed by a recursive descent parser.
+
+// Power is Base^Exponent.
+type Power struct {
+	Base     *big.Int
+	Exponent *big.Int
+}
+
+// Term is a product of powers, subtracted instead of added if Negative is set.
+type Term struct {
+	Negative bool
+	Powers   []Power
+}
+
+// Expr is a sum of terms. The first term is never negative.
+type Expr []Term
+
+// Parse parses s, which must be an expression as a whole.
+// It returns ErrTooLarge if a term of s may have more than MaxBits bits.
+func Parse(s string) (Expr, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	i, e, err := parseExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	if i != len(s) {
+		return nil, ErrSyntax
+	}
+	for _, term := range e {
+		if term.maxBits() > MaxBits {
+			return nil, ErrTooLarge
+		}
+	}
+	return e, nil
+}
+
+// Value returns the value of e.
+func (e Expr) Value() *big.Int {
+	value := new(big.Int)
+	for _, term := range e {
+		if term.Negative {
+			value.Sub(value, term.Value())
+		} else {
+			value.Add(value, term.Value())
+		}
+	}
+	return value
+}
+
+// Value returns the value of t, ignoring its sign.
+func (t Term) Value() *big.Int {
+	value := big.NewInt(1)
+	for _, pow := range t.Powers {
+		value.Mul(value, pow.Value())
+	}
+	return value
+}
+
+// maxBits returns an upper bound of the number of bits of the value of t, or MaxBits + 1 if it exceeds MaxBits.
+func (t Term) maxBits() uint64 {
+	bits := uint64(0)
+	for _, pow := range t.Powers {
+		bits += pow.maxBits()
+		if bits > MaxBits {
+			return MaxBits + 1
+		}
+	}
+	return bits
+}
+
+// maxBits returns an upper bound of the number of bits of the value of p, or MaxBits + 1 if it exceeds MaxBits.
+func (p Power) maxBits() uint64 {
+	if p.Base.BitLen() <= 1 {
+		return 1
+	}
+	if !p.Exponent.IsUint64() || p.Exponent.Uint64() > MaxBits {
+		return MaxBits + 1
+	}
+	return min(uint64(p.Base.BitLen())*p.Exponent.Uint64(), MaxBits+1)
+}
+
+// Value returns the value of p.
+func (p Power) Value() *big.Int {
+	return new(big.Int).Exp(p.Base, p.Exponent, nil)
+}
+
+func parseExpr(s string) (int, Expr, error) {
+	i, term, err := parseTerm(s)
+	if err != nil {
+		return i, nil, err
+	}
+	e := Expr{term}
+	for i < len(s) && (s[i] == '+' || s[i] == '-') {
+		negative := s[i] == '-'
+		i++
+		j, term, err := parseTerm(s[i:])
+		if err != nil {
+			return i + j, nil, err
+		}
+		i += j
+		term.Negative = negative
+		e = append(e, term)
+	}
+	return i, e, nil
+}
+
+func parseTerm(s string) (int, Term, error) {
+	i, pow, err := parsePow(s)
+	if err != nil {
+		return i, Term{}, err
+	}
+	term := Term{Powers: []Power{pow}}
+	for i < len(s) && s[i] == '*' {
+		i++
+		j, pow, err := parsePow(s[i:])
+		if err != nil {
+			return i + j, Term{}, err
+		}
+		i += j
+		term.Powers = append(term.Powers, pow)
+	}
+	return i, term, nil
+}
+
+func parsePow(s string) (int, Power, error) {
+	i, base, err := parseNum(s)
+	if err != nil {
+		return i, Power{}, err
+	}
+	if i < len(s) && s[i] == '^' {
+		i++
+		j, exp, err := parseNum(s[i:])
+		if err != nil {
+			return i + j, Power{}, err
+		}
+		return i + j, Power{Base: base, Exponent: exp}, nil
+	}
+	return i, Power{Base: base, Exponent: big.NewInt(1)}, nil
+}
+
+func parseNum(s string) (int, *big.Int, error) {
+	i := 0
+	for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, nil, ErrSyntax
+	}
+	value, ok := new(big.Int).SetString(s[:i], 10)
+	if !ok {
+		return i, nil, ErrSyntax
+	}
+	return i, value, nil
+}
@@ -0,0 +1,36 @@
+package expr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	e, err := Parse("3^2 * 5 - 2^3 + 1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Expr{
+		{Powers: []Power{{Base: big.NewInt(3), Exponent: big.NewInt(2)}, {Base: big.NewInt(5), Exponent: big.NewInt(1)}}},
+		{Negative: true, Powers: []Power{{Base: big.NewInt(2), Exponent: big.NewInt(3)}}},
+		{Powers: []Power{{Base: big.NewInt(1), Exponent: big.NewInt(1)}}},
+	}, e)
+	assert.Equal(t, big.NewInt(38), e.Value())
+	for _, s := range []string{"", "2^", "*3", "12a", "-1", "2^3^4"} {
+		_, err := Parse(s)
+		assert.ErrorIs(t, err, ErrSyntax, s)
+	}
+}
+
+func TestParseTooLarge(t *testing.T) {
+	for _, s := range []string{"2^99999999999", "3^400000", "2^300000 * 2^300000", "2^99999999999999999999999"} {
+		_, err := Parse(s)
+		assert.ErrorIs(t, err, ErrTooLarge, s)
+	}
+	for _, s := range []string{"2^255 - 19", "2^200000", "1^99999999999999999999999", "0^99999999999"} {
+		_, err := Parse(s)
+		assert.NoError(t, err, s)
+	}
+}
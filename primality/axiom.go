@@ -0,0 +1,72 @@
+package primality
+
+import (
+	"math/big"
+	"slices"
+)
+
+// Axiom declares that N is prime without proving it, e.g. because it was proven by an external tool.
+// Registry.Check treats axioms as proven.
+type Axiom struct {
+	N *BigInt `json:"n"`
+	// Provenance is a free-form description of why N is trusted to be prime.
+	Provenance string `json:"provenance"`
+}
+
+// UsedAxioms returns the axioms that proofs in the registry depend on and that are not proven in the registry,
+// i.e., the assumptions the registry relies on besides its proofs. They are sorted by N.
+func (r *Registry) UsedAxioms() []Axiom {
+	proven := map[string]struct{}{}
+	depended := map[string]struct{}{}
+	for _, proof := range r.Proofs {
+		proven[(*big.Int)(proof.N).String()] = struct{}{}
+		for _, d := range proof.Dep() {
+			depended[d.String()] = struct{}{}
+		}
+	}
+	used := []Axiom{}
+	seen := map[string]struct{}{}
+	for _, axiom := range r.Axioms {
+		n := (*big.Int)(axiom.N).String()
+		if _, ok := proven[n]; ok {
+			continue
+		}
+		if _, ok := depended[n]; !ok {
+			continue
+		}
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		used = append(used, axiom)
+	}
+	sortAxioms(used)
+	return used
+}
+
+// withoutAxioms returns the registry made of the proofs in r that rely on no axiom, directly or transitively.
+func (r *Registry) withoutAxioms() (*Registry, error) {
+	sorted, err := topologicalOrder(r.Proofs)
+	if err != nil {
+		return nil, err
+	}
+	proven := map[string]struct{}{}
+	result := &Registry{Proofs: []Proof{}}
+	for _, proof := range sorted {
+		if slices.ContainsFunc(proof.Dep(), func(d *big.Int) bool {
+			_, ok := proven[d.String()]
+			return !ok
+		}) {
+			continue
+		}
+		proven[(*big.Int)(proof.N).String()] = struct{}{}
+		result.Proofs = append(result.Proofs, proof)
+	}
+	return result, nil
+}
+
+func sortAxioms(axioms []Axiom) {
+	slices.SortStableFunc(axioms, func(a, b Axiom) int {
+		return (*big.Int)(a.N).Cmp((*big.Int)(b.N))
+	})
+}
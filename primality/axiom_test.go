@@ -0,0 +1,63 @@
+package primality
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryAxioms(t *testing.T) {
+	// 11 depends on 5
+	proof11, err := Prove(big.NewInt(11))
+	if !assert.NoError(t, err) {
+		return
+	}
+	axiom5 := Axiom{N: (*BigInt)(big.NewInt(5)), Provenance: "trust me"}
+	axiom7 := Axiom{N: (*BigInt)(big.NewInt(7)), Provenance: "unused"}
+	registry := &Registry{
+		Proofs: []Proof{*proof11},
+		Axioms: []Axiom{axiom7, axiom5},
+	}
+	assert.NoError(t, registry.Check())
+	assert.Equal(t, []Axiom{axiom5}, registry.UsedAxioms())
+	issues := registry.Lint(nil)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, LintAxiom, issues[0].Kind)
+		assert.Equal(t, big.NewInt(7), issues[0].N)
+	}
+
+	closure, err := registry.Closure([]*big.Int{big.NewInt(11)})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Axiom{axiom5}, closure.Axioms)
+		assert.NoError(t, closure.Check())
+	}
+
+	encoded, err := json.Marshal(registry)
+	if assert.NoError(t, err) {
+		var decoded Registry
+		assert.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, registry.Axioms, decoded.Axioms)
+	}
+
+	registry.Axioms = nil
+	assert.ErrorIs(t, registry.Check(), ErrMissingDependency)
+}
+
+func TestMergeAxioms(t *testing.T) {
+	registry := &Registry{}
+	for _, n := range []int64{2, 5, 11} {
+		proof, err := Prove(big.NewInt(n))
+		if !assert.NoError(t, err) {
+			return
+		}
+		registry.Proofs = append(registry.Proofs, *proof)
+	}
+	axiom5 := Axiom{N: (*BigInt)(big.NewInt(5)), Provenance: "trust me"}
+	axiom13 := Axiom{N: (*BigInt)(big.NewInt(13)), Provenance: "trust me too"}
+	merged, err := Merge(registry, &Registry{Axioms: []Axiom{axiom13, axiom5}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Axiom{axiom13}, merged.Axioms)
+	}
+}
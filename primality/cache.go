@@ -0,0 +1,117 @@
+package primality
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+)
+
+// VerifierVersion identifies the rules proofs are verified with.
+// It must be incremented whenever they change, which invalidates caches written by older versions.
+//
+//   - 1: the initial rules.
+//   - 2: axioms satisfy dependencies, proofs with a missing field are rejected,
+//     and proofs are cached by the hash of their content with sorted entries.
+const VerifierVersion = 2
+
+// Cache records which proofs have been verified, so that Registry.CheckWithOptions does not verify them again.
+// Proofs are identified by the SHA-256 hash of their content with the factors and inverses sorted,
+// so reordering them does not make a proof verified again. Unlike Proof.ID, the key keeps the base,
+// since finding the canonical base requires verifying the proof.
+// A Cache is safe for concurrent use.
+type Cache struct {
+	mu       sync.Mutex
+	path     string
+	verified map[string]struct{}
+}
+
+type cacheFile struct {
+	Version  int      `json:"version"`
+	Verified []string `json:"verified"`
+}
+
+// OpenCache opens the cache stored in the file path.
+// If the file does not exist or was written by a different VerifierVersion, the cache starts empty.
+// The file is not written until Save is called.
+func OpenCache(path string) (*Cache, error) {
+	c := &Cache{
+		path:     path,
+		verified: map[string]struct{}{},
+	}
+	dat, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file cacheFile
+	if err := json.Unmarshal(dat, &file); err != nil {
+		return nil, err
+	}
+	if file.Version != VerifierVersion {
+		return c, nil
+	}
+	for _, key := range file.Verified {
+		c.verified[key] = struct{}{}
+	}
+	return c, nil
+}
+
+// Reset forgets every verified proof, forcing them to be verified again.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verified = map[string]struct{}{}
+}
+
+// Save writes the cache to its file.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	file := cacheFile{
+		Version:  VerifierVersion,
+		Verified: make([]string, 0, len(c.verified)),
+	}
+	for key := range c.verified {
+		file.Verified = append(file.Verified, key)
+	}
+	c.mu.Unlock()
+	slices.Sort(file.Verified)
+	dat, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(dat, '\n'), 0o644)
+}
+
+func (c *Cache) contains(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.verified[key]
+	return ok
+}
+
+func (c *Cache) add(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verified[key] = struct{}{}
+}
+
+// cacheKey returns the key of proof in a Cache.
+func cacheKey(proof *Proof) (string, error) {
+	sorted, err := proof.sortedCopy()
+	if err != nil {
+		return "", err
+	}
+	encoded, err := sorted.marshalContent()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:]), nil
+}
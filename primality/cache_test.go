@@ -0,0 +1,94 @@
+package primality
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	cache, err := OpenCache(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	proof, err := Prove(big.NewInt(257))
+	if !assert.NoError(t, err) {
+		return
+	}
+	registry := &Registry{
+		Proofs: []Proof{{N: (*BigInt)(big.NewInt(2))}, *proof},
+	}
+	opts := &CheckOptions{Cache: cache}
+	assert.NoError(t, registry.CheckWithOptions(context.Background(), opts))
+	assert.NoError(t, cache.Save())
+
+	cache, err = OpenCache(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	key, err := cacheKey(proof)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, cache.contains(key))
+	cache.Reset()
+	assert.False(t, cache.contains(key))
+}
+
+func TestCacheVersionMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	dat := []byte(`{"version": 0, "verified": ["0123"]}`)
+	if !assert.NoError(t, os.WriteFile(path, dat, 0o644)) {
+		return
+	}
+	cache, err := OpenCache(path)
+	if assert.NoError(t, err) {
+		assert.False(t, cache.contains("0123"))
+	}
+}
+
+func TestCacheKeyCanonical(t *testing.T) {
+	proof, err := Prove(big.NewInt(181))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var reordered Proof
+	if !assert.NoError(t, json.Unmarshal([]byte(mustMarshal(t, proof)), &reordered)) {
+		return
+	}
+	method := reordered.Method.(*GeneralizedPocklingtonProof)
+	slices.Reverse(method.A.Factorization)
+	slices.Reverse(method.Inverses)
+	key, err := cacheKey(proof)
+	if !assert.NoError(t, err) {
+		return
+	}
+	reorderedKey, err := cacheKey(&reordered)
+	if assert.NoError(t, err) {
+		assert.Equal(t, key, reorderedKey)
+	}
+
+	// the base is kept, so a proof with another base is verified again
+	method.Base = (*BigInt)(new(big.Int).Add((*big.Int)(method.Base), big.NewInt(181)))
+	otherBaseKey, err := cacheKey(&reordered)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, key, otherBaseKey)
+	}
+
+	// a proof that cannot be canonicalized is not cached, but still rejected
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "cache.json"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	registry := &Registry{Proofs: []Proof{{N: (*BigInt)(big.NewInt(5)), Method: &GeneralizedPocklingtonProof{}}}}
+	var depErr *DependencyError
+	assert.ErrorAs(t, registry.CheckWithOptions(context.Background(), &CheckOptions{Cache: cache}), &depErr)
+	assert.Empty(t, cache.verified)
+}
@@ -0,0 +1,115 @@
+package primality
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+type entrySorter interface {
+	// sortEntries sorts the entries of the proof without changing anything else.
+	sortEntries() error
+}
+
+type canonicalizer interface {
+	// Canonicalize brings the proof of N into its canonical form.
+	Canonicalize(N *big.Int) error
+}
+
+// Canonicalize sorts the factorization by prime.
+func (f *FactoredInt) Canonicalize() {
+	slices.SortStableFunc(f.Factorization, func(a, b FactorEntry) int {
+		return (*big.Int)(a.Prime).Cmp((*big.Int)(b.Prime))
+	})
+}
+
+// Canonicalize brings the registry into its canonical form:
+// proofs are sorted topologically and then by N, each proof method is canonicalized
+// if it has a method Canonicalize(N *big.Int) error, and axioms are sorted by N.
+// Apart from reordering, a method may only replace what does not change what is proven,
+// e.g. GeneralizedPocklingtonProof replaces the base of a valid proof with the smallest one that works.
+// Metadata is kept, including Metadata.FetchedAt; signatures and proof IDs leave the latter out.
+// It returns a *CycleError if the proofs depend on each other circularly.
+func (r *Registry) Canonicalize() error {
+	for _, proof := range r.Proofs {
+		if method, ok := proof.Method.(canonicalizer); ok {
+			if err := method.Canonicalize((*big.Int)(proof.N)); err != nil {
+				return fmt.Errorf("failed to canonicalize the proof of %s: %w", (*big.Int)(proof.N).String(), err)
+			}
+		}
+	}
+	sorted, err := topologicalOrder(r.Proofs)
+	if err != nil {
+		return err
+	}
+	r.Proofs = sorted
+	sortAxioms(r.Axioms)
+	return nil
+}
+
+// MarshalCanonical returns the JSON encoding of the canonical form of the registry, followed by a newline.
+// Registries with the same content always yield the same bytes. r is not modified.
+func (r *Registry) MarshalCanonical() ([]byte, error) {
+	canonical, err := r.canonicalCopy()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(canonical, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// canonicalCopy returns the canonical form of a deep copy of r.
+func (r *Registry) canonicalCopy() (*Registry, error) {
+	encoded, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	var canonical Registry
+	if err := json.Unmarshal(encoded, &canonical); err != nil {
+		return nil, err
+	}
+	if err := canonical.Canonicalize(); err != nil {
+		return nil, err
+	}
+	return &canonical, nil
+}
+
+// canonicalCopy returns the canonical form of a deep copy of p without metadata.
+func (p *Proof) canonicalCopy() (*Proof, error) {
+	encoded, err := p.marshalContent()
+	if err != nil {
+		return nil, err
+	}
+	var canonical Proof
+	if err := json.Unmarshal(encoded, &canonical); err != nil {
+		return nil, err
+	}
+	if method, ok := canonical.Method.(canonicalizer); ok {
+		if err := method.Canonicalize((*big.Int)(canonical.N)); err != nil {
+			return nil, err
+		}
+	}
+	return &canonical, nil
+}
+
+// sortedCopy returns a deep copy of p without metadata, whose entries are sorted but otherwise kept as is.
+func (p *Proof) sortedCopy() (*Proof, error) {
+	encoded, err := p.marshalContent()
+	if err != nil {
+		return nil, err
+	}
+	var sorted Proof
+	if err := json.Unmarshal(encoded, &sorted); err != nil {
+		return nil, err
+	}
+	if method, ok := sorted.Method.(entrySorter); ok {
+		if err := method.sortEntries(); err != nil {
+			return nil, err
+		}
+	}
+	return &sorted, nil
+}
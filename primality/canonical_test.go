@@ -0,0 +1,138 @@
+package primality
+
+import (
+	"encoding/json"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryMarshalCanonical(t *testing.T) {
+	proof2 := Proof{N: (*BigInt)(big.NewInt(2))}
+	proof5, err := Prove(big.NewInt(5))
+	if !assert.NoError(t, err) {
+		return
+	}
+	proof181, err := Prove(big.NewInt(181))
+	if !assert.NoError(t, err) {
+		return
+	}
+	proof3, err := Prove(big.NewInt(3))
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the proof of 181 with its factorization and inverses in reverse order
+	var shuffled181 Proof
+	if !assert.NoError(t, json.Unmarshal([]byte(mustMarshal(t, proof181)), &shuffled181)) {
+		return
+	}
+	method := shuffled181.Method.(*GeneralizedPocklingtonProof)
+	if !assert.Greater(t, len(method.A.Factorization), 1) || !assert.Greater(t, len(method.Inverses), 1) {
+		return
+	}
+	slices.Reverse(method.A.Factorization)
+	slices.Reverse(method.Inverses)
+	reg1 := &Registry{Proofs: []Proof{*proof181, *proof3, proof2, *proof5}}
+	reg2 := &Registry{Proofs: []Proof{proof2, *proof5, *proof3, shuffled181}}
+	encoded1, err := reg1.MarshalCanonical()
+	if !assert.NoError(t, err) {
+		return
+	}
+	encoded2, err := reg2.MarshalCanonical()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(encoded1), string(encoded2))
+	// the original registry is not modified
+	assert.Equal(t, method.Inverses[0].Value, reg2.Proofs[3].Method.(*GeneralizedPocklingtonProof).Inverses[0].Value)
+	assert.False(t, method.A.IsCanonical())
+
+	assert.NoError(t, reg2.Canonicalize())
+	order := []*big.Int{}
+	for _, proof := range reg2.Proofs {
+		order = append(order, (*big.Int)(proof.N))
+	}
+	assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(5), big.NewInt(181)}, order)
+	canonical181, err := proof181.canonicalCopy()
+	if assert.NoError(t, err) {
+		assert.Equal(t, *canonical181, reg2.Proofs[3])
+	}
+}
+
+func TestRegistryCanonicalizeBase(t *testing.T) {
+	proof5 := func(base *big.Int, inv int64) Proof {
+		return Proof{
+			N: (*BigInt)(big.NewInt(5)),
+			Method: &GeneralizedPocklingtonProof{
+				A: &FactoredInt{
+					Int:           (*BigInt)(big.NewInt(4)),
+					Factorization: []FactorEntry{{Prime: (*BigInt)(big.NewInt(2)), Exponent: 2}},
+				},
+				Base: (*BigInt)(base),
+				Inverses: []Inverse{
+					{Mod: (*BigInt)(big.NewInt(5)), Value: (*BigInt)(big.NewInt(3)), Inv: (*BigInt)(big.NewInt(inv))},
+				},
+			},
+		}
+	}
+	baseOf := func(reg *Registry) *big.Int {
+		return (*big.Int)(reg.Proofs[len(reg.Proofs)-1].Method.(*GeneralizedPocklingtonProof).Base)
+	}
+	two := Proof{N: (*BigInt)(big.NewInt(2))}
+
+	// a valid proof with a base larger than needed gets the smallest base
+	reg := &Registry{Proofs: []Proof{two, proof5(big.NewInt(13), 2)}}
+	assert.NoError(t, reg.Canonicalize())
+	assert.Equal(t, big.NewInt(2), baseOf(reg))
+	assert.NoError(t, reg.Check())
+	encoded13, err := (&Registry{Proofs: []Proof{two, proof5(big.NewInt(13), 2)}}).MarshalCanonical()
+	assert.NoError(t, err)
+	encoded2, err := (&Registry{Proofs: []Proof{two, proof5(big.NewInt(2), 2)}}).MarshalCanonical()
+	assert.NoError(t, err)
+	assert.Equal(t, string(encoded2), string(encoded13))
+
+	// a huge base is replaced too, searching only below maxCanonicalBase
+	huge, _ := new(big.Int).SetString("1000000000000000000002", 10)
+	reg = &Registry{Proofs: []Proof{two, proof5(huge, 2)}}
+	if assert.NoError(t, reg.Check()) {
+		assert.NoError(t, reg.Canonicalize())
+		assert.Equal(t, big.NewInt(2), baseOf(reg))
+	}
+
+	// an invalid proof keeps its base, so that it stays invalid
+	reg = &Registry{Proofs: []Proof{two, proof5(big.NewInt(13), 3)}}
+	assert.NoError(t, reg.Canonicalize())
+	assert.Equal(t, big.NewInt(13), baseOf(reg))
+	assert.Error(t, reg.Check())
+
+	var missing Registry
+	data := `{"proofs": [{"n": "5", "generalized-pocklington": {"base": "2"}}]}`
+	if assert.NoError(t, json.Unmarshal([]byte(data), &missing)) {
+		_, err := missing.MarshalCanonical()
+		assert.Error(t, err)
+	}
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFactoredIntCanonicalize(t *testing.T) {
+	a := FactoredInt{
+		Int: (*BigInt)(big.NewInt(45)),
+		Factorization: []FactorEntry{
+			{Prime: (*BigInt)(big.NewInt(5)), Exponent: 1},
+			{Prime: (*BigInt)(big.NewInt(3)), Exponent: 2},
+		},
+	}
+	a.Canonicalize()
+	assert.True(t, a.IsCanonical())
+	assert.NoError(t, a.Check())
+}
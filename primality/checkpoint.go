@@ -0,0 +1,176 @@
+package primality
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math/big"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Checkpoint records the progress of the prover, so that an interrupted run can be resumed:
+// the proofs completed and the prime factors of N-1 found for the numbers still being proven.
+// Proofs read from a checkpoint are verified before they are reused, and prime factors are used as hints.
+// The internal state of the factoring methods is not recorded, so a method interrupted starts over when resumed.
+// A Checkpoint is safe for concurrent use.
+type Checkpoint struct {
+	// saveMu serializes saves, so that an older state never replaces a newer one
+	saveMu sync.Mutex
+	mu     sync.Mutex
+	path   string
+	proofs map[string]Proof
+	// factors maps N to the prime factors of N-1 found so far
+	factors map[string]checkpointFactors
+}
+
+type checkpointFile struct {
+	Proofs  []Proof             `json:"proofs"`
+	Factors []checkpointFactors `json:"factors"`
+}
+
+type checkpointFactors struct {
+	N      *BigInt   `json:"n"`
+	Primes []*BigInt `json:"primes"`
+}
+
+// OpenCheckpoint opens the checkpoint stored in the file path.
+// If the file does not exist, the checkpoint starts empty.
+// The file is not written until Save is called.
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{
+		path:    path,
+		proofs:  map[string]Proof{},
+		factors: map[string]checkpointFactors{},
+	}
+	dat, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file checkpointFile
+	if err := json.Unmarshal(dat, &file); err != nil {
+		return nil, err
+	}
+	for _, proof := range file.Proofs {
+		if proof.N == nil {
+			return nil, errors.New("checkpoint: missing n")
+		}
+		c.proofs[(*big.Int)(proof.N).String()] = proof
+	}
+	for _, entry := range file.Factors {
+		if entry.N == nil {
+			return nil, errors.New("checkpoint: missing n")
+		}
+		if slices.Contains(entry.Primes, nil) {
+			return nil, errors.New("checkpoint: missing prime")
+		}
+		c.factors[(*big.Int)(entry.N).String()] = entry
+	}
+	return c, nil
+}
+
+// Len returns the number of proofs in the checkpoint.
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.proofs)
+}
+
+// Save writes the checkpoint to its file.
+// The new file is synced to disk and then replaces the old one atomically,
+// so that an interruption or a crash while saving does not lose the previous checkpoint.
+// The checkpoint can be updated concurrently while it is being written.
+func (c *Checkpoint) Save() error {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	c.mu.Lock()
+	file := checkpointFile{
+		Proofs:  make([]Proof, 0, len(c.proofs)),
+		Factors: make([]checkpointFactors, 0, len(c.factors)),
+	}
+	for _, proof := range c.proofs {
+		file.Proofs = append(file.Proofs, proof)
+	}
+	for _, entry := range c.factors {
+		file.Factors = append(file.Factors, entry)
+	}
+	c.mu.Unlock()
+	slices.SortFunc(file.Proofs, func(a, b Proof) int {
+		return (*big.Int)(a.N).Cmp((*big.Int)(b.N))
+	})
+	slices.SortFunc(file.Factors, func(a, b checkpointFactors) int {
+		return (*big.Int)(a.N).Cmp((*big.Int)(b.N))
+	})
+	dat, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := writeFileSync(tmp, append(dat, '\n')); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// writeFileSync writes data to the file name and syncs it to disk.
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// proof returns the proof of n in the checkpoint, if any.
+func (c *Checkpoint) proof(n *big.Int) (*Proof, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	proof, ok := c.proofs[n.String()]
+	if !ok {
+		return nil, false
+	}
+	return &proof, true
+}
+
+// primes returns the prime factors of n-1 found so far.
+func (c *Checkpoint) primes(n *big.Int) []*big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	primes := []*big.Int{}
+	for _, p := range c.factors[n.String()].Primes {
+		primes = append(primes, (*big.Int)(p))
+	}
+	return primes
+}
+
+// addProof records proof, and forgets the factors found for proving it.
+func (c *Checkpoint) addProof(proof *Proof) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := (*big.Int)(proof.N).String()
+	c.proofs[key] = *proof
+	delete(c.factors, key)
+}
+
+// setPrimes records the prime factors of n-1 found so far.
+func (c *Checkpoint) setPrimes(n *big.Int, primes []*big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := checkpointFactors{N: (*BigInt)(n)}
+	for _, p := range primes {
+		entry.Primes = append(entry.Primes, (*BigInt)(p))
+	}
+	c.factors[n.String()] = entry
+}
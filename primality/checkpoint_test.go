@@ -0,0 +1,146 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	n, ok := new(big.Int).SetString("221360928884514619393", 10)
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	checkpoint, err := OpenCheckpoint(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 0, checkpoint.Len())
+	first, err := ProveRegistry(n, &ProveOptions{Checkpoint: checkpoint})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	checkpoint, err = OpenCheckpoint(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, len(first.Proofs), checkpoint.Len())
+	events := []Progress{}
+	second, err := ProveRegistry(n, &ProveOptions{
+		Workers:    4,
+		Checkpoint: checkpoint,
+		Progress: func(p Progress) {
+			events = append(events, p)
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, events, len(first.Proofs))
+	for _, event := range events {
+		assert.Equal(t, StageProved, event.Stage)
+		assert.True(t, event.Resumed)
+	}
+	firstJSON, err := first.MarshalCanonical()
+	assert.NoError(t, err)
+	secondJSON, err := second.MarshalCanonical()
+	assert.NoError(t, err)
+	assert.Equal(t, string(firstJSON), string(secondJSON))
+}
+
+func TestCheckpointInvalidProof(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	// the inverse is wrong
+	data := `{"proofs": [{"n": "7", "generalized-pocklington": {"a": {"int": "6", "factorization": [{"prime": "2", "exponent": 1}, {"prime": "3", "exponent": 1}]}, "base": "3", "inverses": [{"mod": "7", "value": "5", "inv": "5"}, {"mod": "7", "value": "1", "inv": "1"}]}}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := OpenCheckpoint(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	events := []Progress{}
+	registry, err := ProveRegistry(big.NewInt(7), &ProveOptions{
+		Checkpoint: checkpoint,
+		Progress: func(p Progress) {
+			events = append(events, p)
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, registry.Check())
+	// the proof of 7 in the checkpoint is not reused
+	proved := 0
+	for _, event := range events {
+		if event.Stage == StageProved {
+			proved++
+			assert.False(t, event.Resumed, event.N.String())
+		}
+	}
+	assert.Equal(t, len(registry.Proofs), proved)
+	proof := registry.firstProofs()["7"]
+	if assert.IsType(t, &GeneralizedPocklingtonProof{}, proof.Method) {
+		assert.NotContains(t, proof.Method.(*GeneralizedPocklingtonProof).Inverses, Inverse{
+			Mod:   (*BigInt)(big.NewInt(7)),
+			Value: (*BigInt)(big.NewInt(5)),
+			Inv:   (*BigInt)(big.NewInt(5)),
+		})
+	}
+	// the checkpoint is saved with the new proof
+	checkpoint, err = OpenCheckpoint(path)
+	if assert.NoError(t, err) {
+		saved, ok := checkpoint.proof(big.NewInt(7))
+		if assert.True(t, ok) {
+			assert.NoError(t, saved.Check())
+		}
+	}
+}
+
+func TestCheckpointFactors(t *testing.T) {
+	p := nextPrime(big.NewInt(1<<40 + 12345))
+	q := nextPrime(big.NewInt(1<<41 + 6789))
+	n := new(big.Int)
+	for k := int64(1); !n.ProbablyPrime(20); k++ {
+		n.Mul(p, q)
+		n.Mul(n, big.NewInt(2*k))
+		n.Add(n, big.NewInt(1))
+	}
+	opts := &ProveOptions{
+		Factor: &FactorOptions{
+			RhoIterations: 10,
+			PMinus1:       &PMinus1Options{B1: 10, B2: 10},
+			PPlus1:        &PPlus1Options{B1: 10, B2: 10, Starts: 1},
+			SIQS:          &SIQSOptions{MaxDigits: -1},
+			ECM:           &ECMOptions{B1: 10, B2: 10, Curves: 1},
+		},
+	}
+	_, err := prove(context.Background(), n, nil, opts, nil)
+	assert.ErrorIs(t, err, ErrFactorizationFailed)
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	checkpoint, err := OpenCheckpoint(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	checkpoint.setPrimes(n, []*big.Int{p})
+	if !assert.NoError(t, checkpoint.Save()) {
+		return
+	}
+	checkpoint, err = OpenCheckpoint(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []*big.Int{p}, checkpoint.primes(n))
+	proof, err := prove(context.Background(), n, nil, opts, newTracker(&ProveOptions{Checkpoint: checkpoint}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, proof.Check())
+}
@@ -0,0 +1,61 @@
+package primality
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Closure returns the minimal sub-registry that proves targets,
+// i.e., the proofs of targets and, transitively, of their dependencies.
+// Proofs keep their relative order. If a number is proven more than once, only the first proof is kept.
+// Axioms are included if needed and not proven.
+// It returns an error wrapping ErrMissingDependency if some target or dependency is neither proven nor an axiom in r.
+func (r *Registry) Closure(targets []*big.Int) (*Registry, error) {
+	first := map[string]int{}
+	for i, proof := range r.Proofs {
+		n := (*big.Int)(proof.N).String()
+		if _, ok := first[n]; !ok {
+			first[n] = i
+		}
+	}
+	axioms := map[string]Axiom{}
+	for _, axiom := range r.Axioms {
+		n := (*big.Int)(axiom.N).String()
+		if _, ok := axioms[n]; !ok {
+			axioms[n] = axiom
+		}
+	}
+	needed := map[int]struct{}{}
+	neededAxioms := map[string]struct{}{}
+	closure := &Registry{}
+	stack := append([]*big.Int{}, targets...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		i, ok := first[n.String()]
+		if !ok {
+			axiom, ok := axioms[n.String()]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrMissingDependency, n.String())
+			}
+			if _, ok := neededAxioms[n.String()]; !ok {
+				neededAxioms[n.String()] = struct{}{}
+				closure.Axioms = append(closure.Axioms, axiom)
+			}
+			continue
+		}
+		if _, ok := needed[i]; ok {
+			continue
+		}
+		needed[i] = struct{}{}
+		stack = append(stack, r.Proofs[i].Dep()...)
+	}
+	sortAxioms(closure.Axioms)
+	closure.Proofs = make([]Proof, 0, len(needed))
+	for i, proof := range r.Proofs {
+		if _, ok := needed[i]; ok {
+			closure.Proofs = append(closure.Proofs, proof)
+		}
+	}
+	return closure, nil
+}
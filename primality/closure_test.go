@@ -0,0 +1,31 @@
+package primality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryClosure(t *testing.T) {
+	registry := &Registry{}
+	for _, n := range []int64{2, 3, 5, 7, 11, 13} {
+		proof, err := Prove(big.NewInt(n))
+		if !assert.NoError(t, err) {
+			return
+		}
+		registry.Proofs = append(registry.Proofs, *proof)
+	}
+	closure, err := registry.Closure([]*big.Int{big.NewInt(11)})
+	if assert.NoError(t, err) {
+		assert.NoError(t, closure.Check())
+		ns := []*big.Int{}
+		for _, proof := range closure.Proofs {
+			ns = append(ns, (*big.Int)(proof.N))
+		}
+		assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(5), big.NewInt(11)}, ns)
+	}
+
+	_, err = registry.Closure([]*big.Int{big.NewInt(17)})
+	assert.ErrorIs(t, err, ErrMissingDependency)
+}
@@ -0,0 +1,245 @@
+package primality
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// ProofChange describes how the proof of N differs between two registries.
+type ProofChange struct {
+	N *BigInt `json:"n"`
+	// Fields lists what changed: "method", "base", "factorization", "inverses" or "proof".
+	Fields []string `json:"fields"`
+}
+
+// ClosureChange describes how the set of numbers a target depends on differs between two registries.
+type ClosureChange struct {
+	Target  *BigInt   `json:"target"`
+	Added   []*BigInt `json:"added,omitempty"`
+	Removed []*BigInt `json:"removed,omitempty"`
+}
+
+// RegistryDiff is the difference between two registries computed by Diff.
+// All lists are sorted by number.
+type RegistryDiff struct {
+	Added         []*BigInt       `json:"added"`
+	Removed       []*BigInt       `json:"removed"`
+	Changed       []ProofChange   `json:"changed"`
+	AddedAxioms   []Axiom         `json:"added-axioms"`
+	RemovedAxioms []Axiom         `json:"removed-axioms"`
+	Closures      []ClosureChange `json:"closures"`
+}
+
+// Diff computes the difference from the registry from to the registry to.
+// For each of targets, it reports how the dependency closure changed.
+// If targets is empty, the numbers no other proof depends on in either registry are used.
+// If a number is proven more than once in a registry, only the first proof is considered.
+func Diff(from, to *Registry, targets []*big.Int) *RegistryDiff {
+	oldProofs := from.firstProofs()
+	newProofs := to.firstProofs()
+	diff := &RegistryDiff{
+		Added:         []*BigInt{},
+		Removed:       []*BigInt{},
+		Changed:       []ProofChange{},
+		AddedAxioms:   []Axiom{},
+		RemovedAxioms: []Axiom{},
+		Closures:      []ClosureChange{},
+	}
+	for n, proof := range newProofs {
+		oldProof, ok := oldProofs[n]
+		if !ok {
+			diff.Added = append(diff.Added, proof.N)
+			continue
+		}
+		if fields := diffProofs(oldProof, proof); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, ProofChange{N: proof.N, Fields: fields})
+		}
+	}
+	for n, proof := range oldProofs {
+		if _, ok := newProofs[n]; !ok {
+			diff.Removed = append(diff.Removed, proof.N)
+		}
+	}
+	diff.AddedAxioms = axiomsNotIn(to.Axioms, from.Axioms)
+	diff.RemovedAxioms = axiomsNotIn(from.Axioms, to.Axioms)
+	if len(targets) == 0 {
+		targets = append(from.roots(), to.roots()...)
+	}
+	seen := map[string]struct{}{}
+	for _, target := range targets {
+		if _, ok := seen[target.String()]; ok {
+			continue
+		}
+		seen[target.String()] = struct{}{}
+		oldClosure := from.reachableFrom([]*big.Int{target})
+		newClosure := to.reachableFrom([]*big.Int{target})
+		change := ClosureChange{Target: (*BigInt)(target)}
+		for n := range newClosure {
+			if _, ok := oldClosure[n]; !ok {
+				change.Added = append(change.Added, parseBigInt(n))
+			}
+		}
+		for n := range oldClosure {
+			if _, ok := newClosure[n]; !ok {
+				change.Removed = append(change.Removed, parseBigInt(n))
+			}
+		}
+		if len(change.Added) > 0 || len(change.Removed) > 0 {
+			sortBigInts(change.Added)
+			sortBigInts(change.Removed)
+			diff.Closures = append(diff.Closures, change)
+		}
+	}
+	sortBigInts(diff.Added)
+	sortBigInts(diff.Removed)
+	slices.SortFunc(diff.Changed, func(a, b ProofChange) int {
+		return (*big.Int)(a.N).Cmp((*big.Int)(b.N))
+	})
+	slices.SortFunc(diff.Closures, func(a, b ClosureChange) int {
+		return (*big.Int)(a.Target).Cmp((*big.Int)(b.Target))
+	})
+	return diff
+}
+
+// Empty reports whether no difference was found.
+func (d *RegistryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		len(d.AddedAxioms) == 0 && len(d.RemovedAxioms) == 0 && len(d.Closures) == 0
+}
+
+// String returns a human-readable representation of the difference, one change per line.
+func (d *RegistryDiff) String() string {
+	var b strings.Builder
+	for _, n := range d.Added {
+		fmt.Fprintf(&b, "+ %s\n", (*big.Int)(n).String())
+	}
+	for _, n := range d.Removed {
+		fmt.Fprintf(&b, "- %s\n", (*big.Int)(n).String())
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "~ %s: %s changed\n", (*big.Int)(c.N).String(), strings.Join(c.Fields, ", "))
+	}
+	for _, axiom := range d.AddedAxioms {
+		fmt.Fprintf(&b, "+ axiom %s (%s)\n", (*big.Int)(axiom.N).String(), axiom.Provenance)
+	}
+	for _, axiom := range d.RemovedAxioms {
+		fmt.Fprintf(&b, "- axiom %s (%s)\n", (*big.Int)(axiom.N).String(), axiom.Provenance)
+	}
+	for _, c := range d.Closures {
+		fmt.Fprintf(&b, "closure of %s:", (*big.Int)(c.Target).String())
+		for _, n := range c.Added {
+			fmt.Fprintf(&b, " +%s", (*big.Int)(n).String())
+		}
+		for _, n := range c.Removed {
+			fmt.Fprintf(&b, " -%s", (*big.Int)(n).String())
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func diffProofs(from, to Proof) []string {
+	if (from.Method == nil) != (to.Method == nil) || (from.Method != nil && from.Method.Key() != to.Method.Key()) {
+		return []string{"method"}
+	}
+	oldPocklington, ok1 := from.Method.(*GeneralizedPocklingtonProof)
+	newPocklington, ok2 := to.Method.(*GeneralizedPocklingtonProof)
+	if ok1 && ok2 {
+		fields := []string{}
+		if !jsonEqual(oldPocklington.Base, newPocklington.Base) {
+			fields = append(fields, "base")
+		}
+		// so is the factorization of A
+		if !jsonEqual(sortedFactors(oldPocklington.A), sortedFactors(newPocklington.A)) {
+			fields = append(fields, "factorization")
+		}
+		// inverses are a set; their order does not matter
+		if !jsonEqual(sortedInverses(oldPocklington.Inverses), sortedInverses(newPocklington.Inverses)) {
+			fields = append(fields, "inverses")
+		}
+		return fields
+	}
+	fromContent, errFrom := from.marshalContent()
+	toContent, errTo := to.marshalContent()
+	if errFrom != nil || errTo != nil || !bytes.Equal(fromContent, toContent) {
+		return []string{"proof"}
+	}
+	return nil
+}
+
+// axiomsNotIn returns the axioms in axioms that are not in others, sorted by N.
+func axiomsNotIn(axioms []Axiom, others []Axiom) []Axiom {
+	result := []Axiom{}
+	for _, axiom := range axioms {
+		if !slices.ContainsFunc(others, func(a Axiom) bool { return jsonEqual(a, axiom) }) {
+			result = append(result, axiom)
+		}
+	}
+	sortAxioms(result)
+	return result
+}
+
+func sortedFactors(a *FactoredInt) *FactoredInt {
+	if a == nil {
+		return nil
+	}
+	sorted := &FactoredInt{Int: a.Int, Factorization: slices.Clone(a.Factorization)}
+	sorted.Canonicalize()
+	return sorted
+}
+
+func sortedInverses(invs []Inverse) []Inverse {
+	sorted := slices.Clone(invs)
+	slices.SortFunc(sorted, compareInverses)
+	return sorted
+}
+
+func jsonEqual(a, b any) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// firstProofs maps each number proven in the registry to its first proof.
+func (r *Registry) firstProofs() map[string]Proof {
+	proofs := map[string]Proof{}
+	for _, proof := range r.Proofs {
+		n := (*big.Int)(proof.N).String()
+		if _, ok := proofs[n]; !ok {
+			proofs[n] = proof
+		}
+	}
+	return proofs
+}
+
+// roots returns the numbers proven in the registry that no proof in the registry depends on.
+func (r *Registry) roots() []*big.Int {
+	depended := map[string]struct{}{}
+	for _, proof := range r.Proofs {
+		for _, d := range proof.Dep() {
+			depended[d.String()] = struct{}{}
+		}
+	}
+	roots := []*big.Int{}
+	for _, proof := range r.Proofs {
+		if _, ok := depended[(*big.Int)(proof.N).String()]; !ok {
+			roots = append(roots, (*big.Int)(proof.N))
+		}
+	}
+	return roots
+}
+
+func parseBigInt(s string) *BigInt {
+	n, _ := new(big.Int).SetString(s, 10)
+	return (*BigInt)(n)
+}
+
+func sortBigInts(ns []*BigInt) {
+	slices.SortFunc(ns, func(a, b *BigInt) int {
+		return (*big.Int)(a).Cmp((*big.Int)(b))
+	})
+}
@@ -0,0 +1,72 @@
+package primality
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	proofs := map[int64]Proof{}
+	for _, n := range []int64{2, 3, 5, 7, 11} {
+		proof, err := Prove(big.NewInt(n))
+		if !assert.NoError(t, err) {
+			return
+		}
+		proofs[n] = *proof
+	}
+	// a proof of 7 using A = 6 instead of A = 3
+	proof7 := Proof{
+		N: (*BigInt)(big.NewInt(7)),
+		Method: &GeneralizedPocklingtonProof{
+			A: &FactoredInt{
+				Int: (*BigInt)(big.NewInt(6)),
+				Factorization: []FactorEntry{
+					{Prime: (*BigInt)(big.NewInt(2)), Exponent: 1},
+					{Prime: (*BigInt)(big.NewInt(3)), Exponent: 1},
+				},
+			},
+			Base: (*BigInt)(big.NewInt(3)),
+			Inverses: []Inverse{
+				{
+					Mod:   (*BigInt)(big.NewInt(7)),
+					Value: (*BigInt)(big.NewInt(5)),
+					Inv:   (*BigInt)(big.NewInt(3)),
+				},
+				{
+					Mod:   (*BigInt)(big.NewInt(7)),
+					Value: (*BigInt)(big.NewInt(1)),
+					Inv:   (*BigInt)(big.NewInt(1)),
+				},
+			},
+		},
+	}
+	if !assert.NoError(t, proof7.Check()) {
+		return
+	}
+	from := &Registry{Proofs: []Proof{proofs[2], proofs[3], proofs[7], proofs[5], proofs[11]}}
+	to := &Registry{Proofs: []Proof{proofs[2], proofs[3], proof7}}
+	diff := Diff(from, to, nil)
+	assert.Equal(t, []*BigInt{}, diff.Added)
+	assert.Equal(t, []*BigInt{(*BigInt)(big.NewInt(5)), (*BigInt)(big.NewInt(11))}, diff.Removed)
+	assert.Equal(t, []ProofChange{
+		{N: (*BigInt)(big.NewInt(7)), Fields: []string{"base", "factorization", "inverses"}},
+	}, diff.Changed)
+	assert.Equal(t, []ClosureChange{
+		{Target: (*BigInt)(big.NewInt(11)), Removed: []*BigInt{(*BigInt)(big.NewInt(2)), (*BigInt)(big.NewInt(5))}},
+	}, diff.Closures)
+	assert.Equal(t, "- 5\n- 11\n~ 7: base, factorization, inverses changed\nclosure of 11: -2 -5\n", diff.String())
+	assert.True(t, Diff(from, from, nil).Empty())
+
+	// reordering the factors or inverses is not a change
+	reordered := proof7
+	reorderedMethod := *proof7.Method.(*GeneralizedPocklingtonProof)
+	reorderedMethod.A = &FactoredInt{Int: reorderedMethod.A.Int, Factorization: slices.Clone(reorderedMethod.A.Factorization)}
+	slices.Reverse(reorderedMethod.A.Factorization)
+	reorderedMethod.Inverses = slices.Clone(reorderedMethod.Inverses)
+	slices.Reverse(reorderedMethod.Inverses)
+	reordered.Method = &reorderedMethod
+	assert.True(t, Diff(to, &Registry{Proofs: []Proof{proofs[2], proofs[3], reordered}}, nil).Empty())
+}
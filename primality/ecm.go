@@ -0,0 +1,301 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+)
+
+const (
+	defaultECMB1     = 50000
+	defaultECMCurves = 100
+)
+
+// ECMOptions configures ECM. A nil *ECMOptions uses the defaults.
+type ECMOptions struct {
+	// B1 is the stage 1 bound, at most MaxB1. 0 means 50000.
+	B1 uint64
+	// B2 is the stage 2 bound, at most MaxB2. 0 means 100 * B1, but at most MaxB2. Stage 2 is skipped if B2 <= B1.
+	B2 uint64
+	// Curves is the number of curves tried. 0 means 100.
+	Curves int
+	// Seed determines the curves tried. The same seed gives the same curves, hence the same factor.
+	Seed uint64
+}
+
+func (o *ECMOptions) b1() uint64 {
+	if o == nil || o.B1 == 0 {
+		return defaultECMB1
+	}
+	return o.B1
+}
+
+func (o *ECMOptions) b2() uint64 {
+	if o == nil || o.B2 == 0 {
+		return defaultB2(o.b1())
+	}
+	return o.B2
+}
+
+func (o *ECMOptions) curves() int {
+	if o == nil || o.Curves == 0 {
+		return defaultECMCurves
+	}
+	return o.Curves
+}
+
+func (o *ECMOptions) seed() uint64 {
+	if o == nil {
+		return 0
+	}
+	return o.Seed
+}
+
+// ECM finds a nontrivial factor of the composite n with Lenstra's elliptic curve method,
+// using Montgomery curves with Suyama's parametrization.
+// It returns ErrNoFactorFound if none of the curves yields a factor,
+// and an error wrapping ErrBoundTooLarge if B1 or B2 exceeds its maximum.
+func ECM(ctx context.Context, n *big.Int, opts *ECMOptions) (*big.Int, error) {
+	b1, b2 := opts.b1(), opts.b2()
+	if err := checkBounds(b1, b2); err != nil {
+		return nil, err
+	}
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	primes := primesBelow(b1 + 1)
+	var stage2 []stage2Step
+	if b2 > b1 {
+		stage2 = stage2Steps(b1, b2)
+	}
+	rng := rand.New(rand.NewSource(int64(opts.seed())))
+	for i := 0; i < opts.curves(); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// sigma must not be 0, 1, 3, 5 or -5 mod n
+		sigma := big.NewInt(6 + rng.Int63n(1<<62))
+		c, q, g := newECMCurve(n, sigma)
+		if g != nil {
+			return g, nil
+		}
+		if c == nil {
+			continue
+		}
+		g, err := c.stage1(ctx, q, primes, b1)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil && len(stage2) > 0 {
+			g, err = c.stage2(ctx, q, stage2)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if g != nil {
+			return g, nil
+		}
+	}
+	return nil, ErrNoFactorFound
+}
+
+// ecmPoint is a point on a Montgomery curve in projective X:Z coordinates.
+type ecmPoint struct {
+	x, z *big.Int
+}
+
+func (p ecmPoint) clone() ecmPoint {
+	return ecmPoint{x: new(big.Int).Set(p.x), z: new(big.Int).Set(p.z)}
+}
+
+// ecmCurve is the Montgomery curve B y^2 = x^3 + A x^2 + x modulo n.
+type ecmCurve struct {
+	n *big.Int
+	// a24 is (A + 2) / 4.
+	a24 *big.Int
+	// scratch space
+	t1, t2, t3, t4 *big.Int
+}
+
+// newECMCurve returns the curve and the starting point given by sigma with Suyama's parametrization.
+// If the curve cannot be built because an inversion modulo n fails, it returns the factor found instead,
+// or all nils if the factor is n itself.
+func newECMCurve(n, sigma *big.Int) (*ecmCurve, ecmPoint, *big.Int) {
+	mod := func(x *big.Int) *big.Int {
+		return x.Mod(x, n)
+	}
+	// u = sigma^2 - 5, v = 4 sigma
+	u := mod(new(big.Int).Sub(new(big.Int).Mul(sigma, sigma), big.NewInt(5)))
+	v := mod(new(big.Int).Lsh(sigma, 2))
+	u3 := mod(new(big.Int).Exp(u, big.NewInt(3), n))
+	v3 := mod(new(big.Int).Exp(v, big.NewInt(3), n))
+	// a24 = (v - u)^3 (3u + v) / (16 u^3 v)
+	num := mod(new(big.Int).Exp(new(big.Int).Sub(v, u), big.NewInt(3), n))
+	num = mod(num.Mul(num, new(big.Int).Add(new(big.Int).Mul(u, big.NewInt(3)), v)))
+	den := mod(new(big.Int).Mul(new(big.Int).Lsh(u3, 4), v))
+	g := new(big.Int).GCD(nil, nil, den, n)
+	if g.Cmp(big.NewInt(1)) != 0 {
+		if g.Cmp(n) == 0 {
+			return nil, ecmPoint{}, nil
+		}
+		return nil, ecmPoint{}, g
+	}
+	a24 := mod(num.Mul(num, new(big.Int).ModInverse(den, n)))
+	c := &ecmCurve{
+		n:   n,
+		a24: a24,
+		t1:  new(big.Int),
+		t2:  new(big.Int),
+		t3:  new(big.Int),
+		t4:  new(big.Int),
+	}
+	return c, ecmPoint{x: u3, z: v3}, nil
+}
+
+func (c *ecmCurve) mulMod(z, x, y *big.Int) {
+	z.Mul(x, y)
+	z.Mod(z, c.n)
+}
+
+// double sets r = 2p. r may be p.
+func (c *ecmCurve) double(r, p ecmPoint) {
+	c.t1.Add(p.x, p.z)
+	c.mulMod(c.t1, c.t1, c.t1)
+	c.t2.Sub(p.x, p.z)
+	c.mulMod(c.t2, c.t2, c.t2)
+	c.t3.Sub(c.t1, c.t2)
+	c.mulMod(r.x, c.t1, c.t2)
+	c.mulMod(c.t4, c.a24, c.t3)
+	c.t4.Add(c.t4, c.t2)
+	c.mulMod(r.z, c.t3, c.t4)
+}
+
+// add sets r = p + q, given diff = p - q. r may be p or q, but not diff.
+func (c *ecmCurve) add(r, p, q, diff ecmPoint) {
+	c.t1.Sub(p.x, p.z)
+	c.t2.Add(q.x, q.z)
+	c.mulMod(c.t1, c.t1, c.t2)
+	c.t2.Add(p.x, p.z)
+	c.t3.Sub(q.x, q.z)
+	c.mulMod(c.t2, c.t2, c.t3)
+	c.t3.Add(c.t1, c.t2)
+	c.mulMod(c.t3, c.t3, c.t3)
+	c.t4.Sub(c.t1, c.t2)
+	c.mulMod(c.t4, c.t4, c.t4)
+	c.mulMod(r.x, diff.z, c.t3)
+	c.mulMod(r.z, diff.x, c.t4)
+}
+
+// multiply sets p = k p with the Montgomery ladder. k must be positive.
+func (c *ecmCurve) multiply(p ecmPoint, k uint64) {
+	if k == 1 {
+		return
+	}
+	r0 := p.clone()
+	r1 := ecmPoint{x: new(big.Int), z: new(big.Int)}
+	c.double(r1, p)
+	top := 63
+	for k>>top == 0 {
+		top--
+	}
+	for i := top - 1; i >= 0; i-- {
+		if k>>i&1 == 1 {
+			c.add(r0, r0, r1, p)
+			c.double(r1, r1)
+		} else {
+			c.add(r1, r0, r1, p)
+			c.double(r0, r0)
+		}
+	}
+	p.x.Set(r0.x)
+	p.z.Set(r0.z)
+}
+
+// factorFrom returns gcd(x, n) if it is a nontrivial factor of n, and nil otherwise.
+func (c *ecmCurve) factorFrom(x *big.Int) *big.Int {
+	g := new(big.Int).GCD(nil, nil, x, c.n)
+	if g.Cmp(big.NewInt(1)) == 0 || g.Cmp(c.n) == 0 {
+		return nil
+	}
+	return g
+}
+
+// stage1 multiplies q by every prime power not exceeding b1.
+func (c *ecmCurve) stage1(ctx context.Context, q ecmPoint, primes []uint64, b1 uint64) (*big.Int, error) {
+	for i, p := range primes {
+		if i%pollInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		pe := p
+		for pe <= b1/p {
+			pe *= p
+		}
+		c.multiply(q, pe)
+	}
+	return c.factorFrom(q.z), nil
+}
+
+// stage2 looks for a prime s in (b1, b2] such that s q is the point at infinity modulo a factor of n.
+// Since x(k D q) = x(j q) iff k D = ±j modulo the order of q, a single gcd covers both k D - j and k D + j.
+func (c *ecmCurve) stage2(ctx context.Context, q ecmPoint, steps []stage2Step) (*big.Int, error) {
+	// baby[i] = stage2BabySteps[i] q
+	baby := make([]ecmPoint, len(stage2BabySteps))
+	q2 := q.clone()
+	c.double(q2, q2)
+	// prev = (j - 2) q and cur = j q
+	prev, cur := q.clone(), q.clone()
+	for j, i := uint64(1), 0; i < len(stage2BabySteps); j += 2 {
+		if j > 1 {
+			next := ecmPoint{x: new(big.Int), z: new(big.Int)}
+			if j == 3 {
+				c.add(next, q2, cur, cur)
+			} else {
+				c.add(next, cur, q2, prev)
+			}
+			prev, cur = cur, next
+		}
+		if stage2BabySteps[i] == j {
+			baby[i] = cur.clone()
+			i++
+		}
+	}
+	// giant = k D q
+	step := q.clone()
+	c.multiply(step, stage2D)
+	k := steps[0].k
+	giant := q.clone()
+	c.multiply(giant, k*stage2D)
+	// prevGiant = (k - 1) D q, which is only used if k > 1
+	prevGiant := q.clone()
+	if k > 1 {
+		c.multiply(prevGiant, (k-1)*stage2D)
+	}
+	acc := big.NewInt(1)
+	t := new(big.Int)
+	u := new(big.Int)
+	for _, s := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for k < s.k {
+			next := ecmPoint{x: new(big.Int), z: new(big.Int)}
+			if k == 1 {
+				c.double(next, giant)
+			} else {
+				c.add(next, giant, step, prevGiant)
+			}
+			prevGiant, giant = giant, next
+			k++
+		}
+		for _, i := range s.indices {
+			// x_giant z_j - x_j z_giant
+			c.mulMod(t, giant.x, baby[i].z)
+			c.mulMod(u, baby[i].x, giant.z)
+			t.Sub(t, u)
+			c.mulMod(acc, acc, t)
+		}
+	}
+	return c.factorFrom(acc), nil
+}
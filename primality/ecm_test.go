@@ -0,0 +1,72 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ecmTestInt returns p q, where p has 17 digits, so Pollard's rho method would need about 10^8 iterations.
+func ecmTestInt() (*big.Int, *big.Int) {
+	p := nextPrime(big.NewInt(12345678901234567))
+	q := nextPrime(new(big.Int).Lsh(big.NewInt(1), 400))
+	return new(big.Int).Mul(p, q), p
+}
+
+func TestECM(t *testing.T) {
+	n, p := ecmTestInt()
+	d, err := ECM(context.Background(), n, &ECMOptions{B1: 10000, Curves: 200})
+	assert.NoError(t, err)
+	assert.Equal(t, p, d)
+}
+
+func TestECMDeterministic(t *testing.T) {
+	n, _ := ecmTestInt()
+	for seed := uint64(0); seed < 3; seed++ {
+		opts := &ECMOptions{B1: 2000, B2: 200000, Curves: 5, Seed: seed}
+		d1, err1 := ECM(context.Background(), n, opts)
+		d2, err2 := ECM(context.Background(), n, opts)
+		assert.Equal(t, d1, d2)
+		assert.Equal(t, err1, err2)
+	}
+}
+
+func TestECMNoFactorFound(t *testing.T) {
+	n, _ := ecmTestInt()
+	_, err := ECM(context.Background(), n, &ECMOptions{B1: 10, B2: 10, Curves: 1})
+	assert.ErrorIs(t, err, ErrNoFactorFound)
+}
+
+func TestECMBoundTooLarge(t *testing.T) {
+	n, _ := ecmTestInt()
+	_, err := ECM(context.Background(), n, &ECMOptions{B1: 1 << 63})
+	assert.ErrorIs(t, err, ErrBoundTooLarge)
+	_, err = ECM(context.Background(), n, &ECMOptions{B1: 10, B2: MaxB2 + 1})
+	assert.ErrorIs(t, err, ErrBoundTooLarge)
+	// the default B2 saturates instead of overflowing
+	assert.Equal(t, uint64(MaxB2), (&ECMOptions{B1: 1 << 63}).b2())
+}
+
+func TestECMContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, _ := ecmTestInt()
+	_, err := ECM(ctx, n, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFactorECM(t *testing.T) {
+	n, p := ecmTestInt()
+	q := new(big.Int).Div(n, p)
+	factors, err := FactorContext(context.Background(), n, &FactorOptions{
+		RhoIterations: 1000,
+		ECM:           &ECMOptions{B1: 10000},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []FactorEntry{
+		{Prime: (*BigInt)(p), Exponent: 1},
+		{Prime: (*BigInt)(q), Exponent: 1},
+	}, factors)
+}
@@ -0,0 +1,95 @@
+package primality
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrNoProof              = errors.New("no proof provided")
+	ErrDependencyNotSmaller = errors.New("dependency is not smaller than N")
+	ErrCyclicDependency     = errors.New("cyclic dependency")
+	ErrInvalidKnownRegistry = errors.New("invalid known registry")
+)
+
+// PocklingtonError is returned when a generalized Pocklington proof of N does not verify.
+type PocklingtonError struct {
+	N *big.Int
+	// Method is the JSON key of the proof method, such as GeneralizedPocklingtonKey.
+	Method string
+	// Reason describes the condition that does not hold.
+	Reason string
+	// Factor is the prime factor of A the failure is about, or nil if the failure is not about a specific factor.
+	Factor *big.Int
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *PocklingtonError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+	}
+	return e.Reason
+}
+
+func (e *PocklingtonError) Unwrap() error {
+	return e.Err
+}
+
+// DependencyError is returned by Registry.Check when a proof in the registry is incorrect or incomplete.
+type DependencyError struct {
+	// Chain is the chain of dependencies that led to the failure.
+	// Chain[0] is a number no other proof depends on, each element depends on the next one,
+	// and the last element is the number whose proof failed.
+	Chain []*big.Int
+	// Proof is the index of the failed proof in the proofs of the registry checked.
+	Proof int
+	Err   error
+}
+
+// N returns the number whose proof failed.
+func (e *DependencyError) N() *big.Int {
+	return e.Chain[len(e.Chain)-1]
+}
+
+func (e *DependencyError) Error() string {
+	if len(e.Chain) == 1 {
+		return fmt.Sprintf("error in verifying %s: %v", e.N().String(), e.Err)
+	}
+	chain := make([]string, len(e.Chain))
+	for i, n := range e.Chain {
+		chain[i] = n.String()
+	}
+	return fmt.Sprintf(
+		"error in verifying %s (dependency chain: %s): %v",
+		e.N().String(),
+		strings.Join(chain, " -> "),
+		e.Err,
+	)
+}
+
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+// CycleError is returned by Registry.Check when proofs in the registry depend on each other circularly.
+type CycleError struct {
+	// Cycle lists the numbers in the cycle. Each element depends on the next one,
+	// and the last element depends on the first one.
+	Cycle []*big.Int
+}
+
+func (e *CycleError) Error() string {
+	cycle := make([]string, len(e.Cycle)+1)
+	for i, n := range e.Cycle {
+		cycle[i] = n.String()
+	}
+	cycle[len(e.Cycle)] = e.Cycle[0].String()
+	return fmt.Sprintf("%v: %s", ErrCyclicDependency, strings.Join(cycle, " -> "))
+}
+
+func (e *CycleError) Unwrap() error {
+	return ErrCyclicDependency
+}
@@ -0,0 +1,388 @@
+package primality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrNoFactorFound is returned by a factoring method that gave up without finding a factor.
+	ErrNoFactorFound = errors.New("no factor found")
+	// ErrFactorizationFailed is returned by Factor when some composite factor could not be split.
+	ErrFactorizationFailed = errors.New("factorization failed")
+	// ErrBoundTooLarge is returned by a factoring method whose bound exceeds its maximum, e.g. MaxB1.
+	ErrBoundTooLarge = errors.New("bound too large")
+)
+
+const (
+	defaultTrialDivisionBound = 1 << 16
+	defaultRhoIterations      = 1 << 20
+)
+
+// FactorOptions configures Factor. A nil *FactorOptions uses the defaults.
+type FactorOptions struct {
+	// Hints are numbers, typically known prime factors, tried as divisors before the factoring methods.
+	// A hint need not be prime or divide the number being factored.
+	Hints []*big.Int
+	// TrialDivisionBound is the bound below which prime factors are found by trial division.
+	// 0 means 65536.
+	TrialDivisionBound uint64
+	// RhoIterations is the maximum number of iterations of Pollard's rho method per composite factor.
+	// 0 means 2^20.
+	RhoIterations int
+	// PMinus1 configures Pollard's p-1 method, which is tried on composite factors Pollard's rho method cannot split.
+	PMinus1 *PMinus1Options
+	// PPlus1 configures Williams' p+1 method, which is tried on composite factors the p-1 method cannot split.
+	PPlus1 *PPlus1Options
+	// SIQS configures the self-initialising quadratic sieve,
+	// which is tried on small enough composite factors the methods above cannot split.
+	SIQS *SIQSOptions
+	// ECM configures the elliptic curve method, which is tried on composite factors the other methods cannot split.
+	ECM *ECMOptions
+	// Progress, if not nil, is called after trial division, whenever a prime factor is found
+	// and before each method is tried on a composite factor.
+	Progress func(FactorProgress)
+}
+
+// FactorProgress describes a factorization in progress.
+type FactorProgress struct {
+	// Primes are the distinct prime factors found so far, in order of discovery.
+	Primes []*big.Int
+	// Remaining is the product of the factors not known to be prime yet.
+	Remaining *big.Int
+	// Composite is the composite factor about to be split, or nil if no method is about to be tried.
+	Composite *big.Int
+	// Method names the method about to be tried on Composite:
+	// "hints", "rho", "p-1", "p+1", "siqs" or "ecm".
+	Method string
+}
+
+func (o *FactorOptions) trialDivisionBound() uint64 {
+	if o == nil || o.TrialDivisionBound == 0 {
+		return defaultTrialDivisionBound
+	}
+	return o.TrialDivisionBound
+}
+
+func (o *FactorOptions) hints() []*big.Int {
+	if o == nil {
+		return nil
+	}
+	return o.Hints
+}
+
+func (o *FactorOptions) rhoIterations() int {
+	if o == nil || o.RhoIterations == 0 {
+		return defaultRhoIterations
+	}
+	return o.RhoIterations
+}
+
+func (o *FactorOptions) pMinus1() *PMinus1Options {
+	if o == nil {
+		return nil
+	}
+	return o.PMinus1
+}
+
+func (o *FactorOptions) pPlus1() *PPlus1Options {
+	if o == nil {
+		return nil
+	}
+	return o.PPlus1
+}
+
+func (o *FactorOptions) siqs() *SIQSOptions {
+	if o == nil {
+		return nil
+	}
+	return o.SIQS
+}
+
+func (o *FactorOptions) ecm() *ECMOptions {
+	if o == nil {
+		return nil
+	}
+	return o.ECM
+}
+
+// splitter finds a nontrivial factor of n, which is composite, odd and not a perfect power.
+// It returns ErrNoFactorFound if it gives up.
+type splitter struct {
+	// name is reported as FactorProgress.Method.
+	name  string
+	split func(ctx context.Context, n *big.Int) (*big.Int, error)
+}
+
+// splitters returns the factoring methods tried in order on each composite factor.
+func (o *FactorOptions) splitters() []splitter {
+	return []splitter{
+		{"hints", func(ctx context.Context, n *big.Int) (*big.Int, error) {
+			return splitByHints(n, o.hints())
+		}},
+		{"rho", func(ctx context.Context, n *big.Int) (*big.Int, error) {
+			return PollardRho(ctx, n, o.rhoIterations())
+		}},
+		{"p-1", func(ctx context.Context, n *big.Int) (*big.Int, error) {
+			return PollardPMinus1(ctx, n, o.pMinus1())
+		}},
+		{"p+1", func(ctx context.Context, n *big.Int) (*big.Int, error) {
+			return WilliamsPPlus1(ctx, n, o.pPlus1())
+		}},
+		{"siqs", func(ctx context.Context, n *big.Int) (*big.Int, error) {
+			if len(n.String()) > o.siqs().maxDigits() {
+				return nil, ErrNoFactorFound
+			}
+			return SIQS(ctx, n, o.siqs())
+		}},
+		{"ecm", func(ctx context.Context, n *big.Int) (*big.Int, error) {
+			return ECM(ctx, n, o.ecm())
+		}},
+	}
+}
+
+// Factor returns the prime factorization of n > 0, sorted by prime.
+// Prime factors below a bound are found by trial division, and the remaining composite factors are split
+// by common factors with the hints, Pollard's rho method, Pollard's p-1 method, Williams' p+1 method,
+// the self-initialising quadratic sieve if they have at most 70 digits, and the elliptic curve method, in this order.
+// Factors are only probable primes; their primality is not proven.
+// It returns an error wrapping ErrFactorizationFailed if some composite factor cannot be split.
+func Factor(n *big.Int) ([]FactorEntry, error) {
+	return FactorContext(context.Background(), n, nil)
+}
+
+// FactorContext is like Factor, but configured by opts and gives up and returns ctx.Err() when ctx is done.
+func FactorContext(ctx context.Context, n *big.Int, opts *FactorOptions) ([]FactorEntry, error) {
+	factors, unfactored, err := factorPartial(ctx, n, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(unfactored) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrFactorizationFailed, unfactored[0].String())
+	}
+	return factors, nil
+}
+
+// factorPartial factors n as far as it can.
+// It returns the prime factors found, sorted by prime, and the composite factors it could not split.
+// If enough is not nil, factorPartial stops splitting composite factors as soon as enough returns true
+// for the product of the prime powers found so far, and returns the composite factors left as unfactored.
+func factorPartial(ctx context.Context, n *big.Int, opts *FactorOptions, enough func(factored *big.Int) bool) ([]FactorEntry, []*big.Int, error) {
+	if n.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("cannot factor %s", n.String())
+	}
+	exponents := map[string]int{}
+	primes := []*big.Int{}
+	// the product of the prime powers found so far
+	factored := big.NewInt(1)
+	addPrime := func(p *big.Int, e int) {
+		key := p.String()
+		if _, ok := exponents[key]; !ok {
+			primes = append(primes, p)
+		}
+		exponents[key] += e
+		factored.Mul(factored, new(big.Int).Exp(p, big.NewInt(int64(e)), nil))
+	}
+	// report calls opts.Progress, if any, with the state of the factorization
+	report := func(composite *big.Int, method string) {
+		if opts == nil || opts.Progress == nil {
+			return
+		}
+		remaining := new(big.Int).Set(n)
+		for _, p := range primes {
+			remaining.Div(remaining, new(big.Int).Exp(p, big.NewInt(int64(exponents[p.String()])), nil))
+		}
+		opts.Progress(FactorProgress{
+			Primes:    slices.Clone(primes),
+			Remaining: remaining,
+			Composite: composite,
+			Method:    method,
+		})
+	}
+	rem, err := trialDivide(ctx, n, opts.trialDivisionBound(), addPrime)
+	if err != nil {
+		return nil, nil, err
+	}
+	report(nil, "")
+	unfactored := []*big.Int{}
+	// composites to split, each with the exponent it appears with
+	type power struct {
+		n *big.Int
+		e int
+	}
+	stack := []power{}
+	if rem.Cmp(big.NewInt(1)) > 0 {
+		stack = append(stack, power{n: rem, e: 1})
+	}
+	splitters := opts.splitters()
+	for len(stack) > 0 {
+		if enough != nil && enough(factored) {
+			for _, cur := range stack {
+				for i := 0; i < cur.e; i++ {
+					unfactored = append(unfactored, cur.n)
+				}
+			}
+			break
+		}
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if cur.n.ProbablyPrime(20) {
+			addPrime(cur.n, cur.e)
+			report(nil, "")
+			continue
+		}
+		if base, k := perfectPower(cur.n); k > 1 {
+			stack = append(stack, power{n: base, e: cur.e * k})
+			continue
+		}
+		var factor *big.Int
+		for _, s := range splitters {
+			report(cur.n, s.name)
+			factor, err = s.split(ctx, cur.n)
+			if err == nil {
+				break
+			}
+			if !errors.Is(err, ErrNoFactorFound) {
+				return nil, nil, err
+			}
+		}
+		if factor == nil {
+			for i := 0; i < cur.e; i++ {
+				unfactored = append(unfactored, cur.n)
+			}
+			continue
+		}
+		// split cur.n = factor * other, taking common factors into account
+		other := new(big.Int).Div(cur.n, factor)
+		g := new(big.Int).GCD(nil, nil, factor, other)
+		if g.Cmp(big.NewInt(1)) == 0 {
+			stack = append(stack, power{n: factor, e: cur.e}, power{n: other, e: cur.e})
+			continue
+		}
+		stack = append(stack, power{n: g, e: cur.e * 2})
+		for _, m := range []*big.Int{factor, other} {
+			if q := new(big.Int).Div(m, g); q.Cmp(big.NewInt(1)) > 0 {
+				stack = append(stack, power{n: q, e: cur.e})
+			}
+		}
+	}
+	slices.SortFunc(primes, func(a, b *big.Int) int {
+		return a.Cmp(b)
+	})
+	factors := make([]FactorEntry, len(primes))
+	for i, p := range primes {
+		factors[i] = FactorEntry{Prime: (*BigInt)(p), Exponent: exponents[p.String()]}
+	}
+	return factors, unfactored, nil
+}
+
+// splitByHints returns a nontrivial common factor of n and a hint, if any.
+func splitByHints(n *big.Int, hints []*big.Int) (*big.Int, error) {
+	g := new(big.Int)
+	for _, h := range hints {
+		g.GCD(nil, nil, n, h)
+		if g.Cmp(big.NewInt(1)) != 0 && g.Cmp(n) != 0 {
+			return g, nil
+		}
+	}
+	return nil, ErrNoFactorFound
+}
+
+var (
+	smallPrimesOnce sync.Once
+	smallPrimes     []uint64
+)
+
+// primesBelow returns the primes below bound.
+func primesBelow(bound uint64) []uint64 {
+	if bound == defaultTrialDivisionBound {
+		smallPrimesOnce.Do(func() {
+			smallPrimes = sieve(defaultTrialDivisionBound)
+		})
+		return smallPrimes
+	}
+	return sieve(bound)
+}
+
+func sieve(bound uint64) []uint64 {
+	composite := make([]bool, bound)
+	primes := []uint64{}
+	for i := uint64(2); i < bound; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, i)
+		for j := i * i; j < bound; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// trialDivide divides n by the primes below bound, calling found for each prime factor.
+// It returns the remaining cofactor.
+func trialDivide(ctx context.Context, n *big.Int, bound uint64, found func(p *big.Int, e int)) (*big.Int, error) {
+	rem := new(big.Int).Set(n)
+	q, r := new(big.Int), new(big.Int)
+	for i, p := range primesBelow(bound) {
+		if i%pollInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		bp := new(big.Int).SetUint64(p)
+		if new(big.Int).Mul(bp, bp).Cmp(rem) > 0 {
+			break
+		}
+		e := 0
+		for {
+			q.QuoRem(rem, bp, r)
+			if r.Sign() != 0 {
+				break
+			}
+			rem.Set(q)
+			e++
+		}
+		if e > 0 {
+			found(bp, e)
+		}
+	}
+	return rem, nil
+}
+
+// perfectPower returns (b, k) with n = b^k and k as large as possible.
+func perfectPower(n *big.Int) (*big.Int, int) {
+	for k := n.BitLen(); k >= 2; k-- {
+		b := intRoot(n, k)
+		if new(big.Int).Exp(b, big.NewInt(int64(k)), nil).Cmp(n) == 0 {
+			return b, k
+		}
+	}
+	return n, 1
+}
+
+// intRoot returns the floor of the k-th root of n >= 0.
+func intRoot(n *big.Int, k int) *big.Int {
+	if n.Sign() == 0 {
+		return new(big.Int)
+	}
+	// Newton's method starting from a power of two above the root
+	x := new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()/k+1))
+	kBig := big.NewInt(int64(k))
+	kMinus1 := big.NewInt(int64(k - 1))
+	for {
+		// y = ((k-1) x + n / x^(k-1)) / k
+		y := new(big.Int).Exp(x, kMinus1, nil)
+		y.Div(n, y)
+		y.Add(y, new(big.Int).Mul(kMinus1, x))
+		y.Div(y, kBig)
+		if y.Cmp(x) >= 0 {
+			return x
+		}
+		x = y
+	}
+}
@@ -0,0 +1,193 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nextPrime returns the smallest probable prime >= n.
+func nextPrime(n *big.Int) *big.Int {
+	p := new(big.Int).Set(n)
+	for !p.ProbablyPrime(20) {
+		p.Add(p, big.NewInt(1))
+	}
+	return p
+}
+
+func product(factors []FactorEntry) *big.Int {
+	prod := big.NewInt(1)
+	for _, entry := range factors {
+		prod.Mul(prod, new(big.Int).Exp((*big.Int)(entry.Prime), big.NewInt(int64(entry.Exponent)), nil))
+	}
+	return prod
+}
+
+func TestFactorSmall(t *testing.T) {
+	for i := int64(1); i < 2000; i++ {
+		factors, err := Factor(big.NewInt(i))
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(i), product(factors))
+		f := FactoredInt{Int: (*BigInt)(big.NewInt(i)), Factorization: factors}
+		assert.True(t, f.IsCanonical())
+		for _, entry := range factors {
+			assert.True(t, (*big.Int)(entry.Prime).ProbablyPrime(20))
+		}
+	}
+}
+
+func TestFactorTwoLargePrimes(t *testing.T) {
+	p := nextPrime(big.NewInt(1<<40 + 12345))
+	q := nextPrime(big.NewInt(1<<41 + 6789))
+	n := new(big.Int).Mul(p, q)
+	factors, err := Factor(n)
+	assert.NoError(t, err)
+	assert.Equal(t, []FactorEntry{
+		{Prime: (*BigInt)(p), Exponent: 1},
+		{Prime: (*BigInt)(q), Exponent: 1},
+	}, factors)
+}
+
+func TestFactorPowers(t *testing.T) {
+	p := nextPrime(big.NewInt(1<<30 + 1))
+	q := nextPrime(big.NewInt(1<<31 + 1))
+	// n = 3 * p^3 * q^2
+	n := new(big.Int).Exp(p, big.NewInt(3), nil)
+	n.Mul(n, new(big.Int).Mul(q, q))
+	n.Mul(n, big.NewInt(3))
+	factors, err := Factor(n)
+	assert.NoError(t, err)
+	assert.Equal(t, []FactorEntry{
+		{Prime: (*BigInt)(big.NewInt(3)), Exponent: 1},
+		{Prime: (*BigInt)(p), Exponent: 3},
+		{Prime: (*BigInt)(q), Exponent: 2},
+	}, factors)
+}
+
+func TestFactorFailed(t *testing.T) {
+	p := nextPrime(big.NewInt(1<<40 + 12345))
+	q := nextPrime(big.NewInt(1<<41 + 6789))
+	n := new(big.Int).Mul(p, q)
+	_, err := FactorContext(context.Background(), n, &FactorOptions{
+		RhoIterations: 10,
+		PMinus1:       &PMinus1Options{B1: 10, B2: 10},
+		PPlus1:        &PPlus1Options{B1: 10, B2: 10, Starts: 1},
+		SIQS:          &SIQSOptions{MaxDigits: -1},
+		ECM:           &ECMOptions{B1: 10, B2: 10, Curves: 1},
+	})
+	assert.ErrorIs(t, err, ErrFactorizationFailed)
+}
+
+func TestFactorContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := FactorContext(ctx, big.NewInt(1<<40+1), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPollardRho(t *testing.T) {
+	p := nextPrime(big.NewInt(1 << 35))
+	q := nextPrime(big.NewInt(1<<36 + 1))
+	n := new(big.Int).Mul(p, q)
+	d, err := PollardRho(context.Background(), n, 1<<24)
+	assert.NoError(t, err)
+	assert.True(t, d.Cmp(p) == 0 || d.Cmp(q) == 0)
+}
+
+// N-1 = 2 * k * p * q with p and q around 2^40, which trial division cannot factor in reasonable time.
+func TestProveTwoLargeFactors(t *testing.T) {
+	p := nextPrime(big.NewInt(1<<40 + 12345))
+	q := nextPrime(big.NewInt(1<<41 + 6789))
+	n := new(big.Int).Mul(p, q)
+	n.Lsh(n, 1)
+	n.Add(n, big.NewInt(1))
+	for k := int64(1); !n.ProbablyPrime(20); k++ {
+		n.Mul(p, q)
+		n.Mul(n, big.NewInt(2*k+2))
+		n.Add(n, big.NewInt(1))
+	}
+	proof, err := Prove(n)
+	assert.NoError(t, err)
+	assert.NoError(t, proof.Check())
+}
+
+func TestFactorHints(t *testing.T) {
+	p := nextPrime(new(big.Int).Lsh(big.NewInt(1), 150))
+	q := nextPrime(new(big.Int).Lsh(big.NewInt(1), 160))
+	n := new(big.Int).Mul(p, q)
+	n.Mul(n, q)
+	opts := &FactorOptions{
+		Hints:         []*big.Int{big.NewInt(12345), q},
+		RhoIterations: 10,
+		PMinus1:       &PMinus1Options{B1: 10, B2: 10},
+		PPlus1:        &PPlus1Options{B1: 10, B2: 10, Starts: 1},
+		SIQS:          &SIQSOptions{MaxDigits: -1},
+		ECM:           &ECMOptions{B1: 10, B2: 10, Curves: 1},
+	}
+	factors, err := FactorContext(context.Background(), n, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []FactorEntry{
+		{Prime: (*BigInt)(p), Exponent: 1},
+		{Prime: (*BigInt)(q), Exponent: 2},
+	}, factors)
+
+	opts.Hints = nil
+	_, err = FactorContext(context.Background(), n, opts)
+	assert.ErrorIs(t, err, ErrFactorizationFailed)
+}
+
+func TestFactorProgress(t *testing.T) {
+	p := nextPrime(big.NewInt(1 << 35))
+	q := nextPrime(big.NewInt(1<<36 + 1))
+	n := new(big.Int).Mul(p, q)
+	n.Mul(n, big.NewInt(12))
+	reports := []FactorProgress{}
+	_, err := FactorContext(context.Background(), n, &FactorOptions{
+		Progress: func(progress FactorProgress) {
+			reports = append(reports, progress)
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// after trial division, 2 and 3 are found and p q remains
+	assert.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(3)}, reports[0].Primes)
+	assert.Equal(t, new(big.Int).Mul(p, q), reports[0].Remaining)
+	assert.Nil(t, reports[0].Composite)
+	assert.Equal(t, "hints", reports[1].Method)
+	assert.Equal(t, new(big.Int).Mul(p, q), reports[1].Composite)
+	last := reports[len(reports)-1]
+	assert.Len(t, last.Primes, 4)
+	assert.Equal(t, big.NewInt(1), last.Remaining)
+}
+
+func TestFactorPartialEnough(t *testing.T) {
+	p := nextPrime(big.NewInt(1<<40 + 12345))
+	q := nextPrime(big.NewInt(1<<41 + 6789))
+	// n = 2^200 p q, where 2^200 alone is a valid A
+	n := new(big.Int).Mul(p, q)
+	n.Lsh(n, 200)
+	methods := []string{}
+	opts := &FactorOptions{
+		Progress: func(progress FactorProgress) {
+			if progress.Method != "" {
+				methods = append(methods, progress.Method)
+			}
+		},
+	}
+	a, err := findA(context.Background(), n, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(1), 200), (*big.Int)(a.Int))
+	assert.Empty(t, methods)
+
+	// without a stopping condition, p q is split
+	factors, unfactored, err := factorPartial(context.Background(), n, opts, nil)
+	assert.NoError(t, err)
+	assert.Len(t, factors, 3)
+	assert.Empty(t, unfactored)
+	assert.NotEmpty(t, methods)
+}
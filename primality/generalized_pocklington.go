@@ -1,67 +1,185 @@
 package primality
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"math/big"
-	"reflect"
+	"slices"
 )
 
+const GeneralizedPocklingtonKey = "generalized-pocklington"
+
+func init() {
+	RegisterProofMethod(GeneralizedPocklingtonKey, func() ProofMethod {
+		return &GeneralizedPocklingtonProof{}
+	})
+}
+
 type GeneralizedPocklingtonProof struct {
 	A        *FactoredInt `json:"a,omitempty"` // N = A * B
 	Base     *BigInt      `json:"base,omitempty"`
 	Inverses []Inverse    `json:"inverses,omitempty"`
 }
 
+func (p *GeneralizedPocklingtonProof) Key() string {
+	return GeneralizedPocklingtonKey
+}
+
 func (p *GeneralizedPocklingtonProof) Check(N *big.Int) error {
+	return p.CheckContext(context.Background(), N)
+}
+
+// CheckContext is like Check, but gives up and returns ctx.Err() when ctx is done.
+// Failures are reported as *PocklingtonError.
+func (p *GeneralizedPocklingtonProof) CheckContext(ctx context.Context, N *big.Int) error {
+	fail := func(reason string, factor *big.Int, err error) error {
+		return &PocklingtonError{N: N, Method: GeneralizedPocklingtonKey, Reason: reason, Factor: factor, Err: err}
+	}
+	if p.missingField() {
+		return fail("missing field", nil, nil)
+	}
 	if err := p.A.Check(); err != nil {
-		return errors.Join(fmt.Errorf("invalid A in verifying %s", N.String()), err)
+		return fail("invalid A", nil, err)
 	}
 	A := (*big.Int)(p.A.Int)
 	NMinus1 := big.NewInt(0).Sub(N, big.NewInt(1))
 	B, NModA := big.NewInt(0).DivMod(NMinus1, A, big.NewInt(0))
 	if NModA.Cmp(big.NewInt(0)) != 0 {
-		return fmt.Errorf("pocklington: N-1 is not divisible by A: not (%s | %s)", A.String(), NMinus1.String())
+		return fail(fmt.Sprintf("pocklington: N-1 is not divisible by A: not (%s | %s)", A.String(), NMinus1.String()), nil, nil)
 	}
 	if B.Cmp(A) >= 0 {
-		return fmt.Errorf("A^2 > N must hold")
+		return fail("A^2 > N must hold", nil, nil)
 	}
 	if B.ModInverse(B, A) == nil {
-		return fmt.Errorf("pocklington: gcd(A, B) != 1")
+		return fail("pocklington: gcd(A, B) != 1", nil, nil)
+	}
+	// values maps base^((N-1)/p) - 1 mod N to the first prime factor p of A it is computed from
+	values := map[string]*big.Int{}
+	factorValues := make([]string, len(p.A.Factorization))
+	for i, entry := range p.A.Factorization {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pr := (*big.Int)(entry.Prime)
+		exp := big.NewInt(0).Div(NMinus1, pr)
+		value := big.NewInt(0).Exp((*big.Int)(p.Base), exp, N)
+		value.Sub(value, big.NewInt(1))
+		value.Mod(value, N)
+		factorValues[i] = value.String()
+		if _, ok := values[factorValues[i]]; !ok {
+			values[factorValues[i]] = pr
+		}
 	}
 	fromInverse := map[string]struct{}{}
 	for _, inv := range p.Inverses {
+		invString := (*big.Int)(inv.Value).String()
+		// the factor whose value the inverse is claimed for, if any
+		factor := values[invString]
 		if err := inv.Check(); err != nil {
-			return err
+			return fail("invalid inverse", factor, err)
 		}
 		if (*big.Int)(inv.Mod).Cmp(N) != 0 {
-			return fmt.Errorf("invalid modulus in inverse")
+			return fail("invalid modulus in inverse", factor, nil)
 		}
-		invString := (*big.Int)(inv.Value).String()
 		if _, ok := fromInverse[invString]; ok {
-			return fmt.Errorf("duplicate inverse")
+			return fail("duplicate inverse", factor, nil)
 		}
 		fromInverse[invString] = struct{}{}
 	}
-	fromBase := map[string]struct{}{}
-	for _, entry := range p.A.Factorization {
-		pr := (*big.Int)(entry.Prime)
-		exp := big.NewInt(0).Div(NMinus1, pr)
-		value := big.NewInt(0).Exp((*big.Int)(p.Base), exp, N)
-		value.Sub(value, big.NewInt(1))
-		value.Mod(value, N)
-		fromBase[value.String()] = struct{}{}
+	for i, entry := range p.A.Factorization {
+		if _, ok := fromInverse[factorValues[i]]; !ok {
+			return fail("set of inverses is not correct", (*big.Int)(entry.Prime), nil)
+		}
 	}
-	if !reflect.DeepEqual(fromInverse, fromBase) {
-		return fmt.Errorf("set of inverses is not correct")
+	if len(values) != len(fromInverse) {
+		return fail("set of inverses is not correct", nil, nil)
 	}
 	return nil
 }
 
+// missingField reports whether a field the proof needs is missing, as in a proof decoded from incomplete JSON.
+func (p *GeneralizedPocklingtonProof) missingField() bool {
+	if p.A == nil || p.A.Int == nil || p.Base == nil {
+		return true
+	}
+	for _, entry := range p.A.Factorization {
+		if entry.Prime == nil {
+			return true
+		}
+	}
+	for _, inv := range p.Inverses {
+		if inv.Mod == nil || inv.Value == nil || inv.Inv == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *GeneralizedPocklingtonProof) Dep() []*big.Int {
 	dep := []*big.Int{}
+	if p.A == nil {
+		return dep
+	}
 	for _, entry := range p.A.Factorization {
-		dep = append(dep, (*big.Int)(entry.Prime))
+		if entry.Prime != nil {
+			dep = append(dep, (*big.Int)(entry.Prime))
+		}
 	}
 	return dep
 }
+
+// DepsSmallerThanN reports whether the dependencies must be strictly smaller than N.
+// Every prime factor of A divides N-1, so this is always the case for a valid proof.
+func (p *GeneralizedPocklingtonProof) DepsSmallerThanN() bool {
+	return true
+}
+
+// maxCanonicalBase bounds the bases Canonicalize tries, so that canonicalizing a proof with a huge base stays cheap.
+const maxCanonicalBase = 1 << 16
+
+// Canonicalize sorts the entries of the proof as sortEntries does.
+// If the proof of N is valid, it also replaces the base with the smallest one that makes a valid proof with the same A,
+// recomputing the inverses. Only bases below both the current one and maxCanonicalBase are tried;
+// if none of them works, the base is kept. An invalid proof is only reordered, so it stays invalid.
+// It returns an error if a field the proof needs is missing.
+func (p *GeneralizedPocklingtonProof) Canonicalize(N *big.Int) error {
+	if err := p.sortEntries(); err != nil {
+		return err
+	}
+	if p.Check(N) == nil {
+		limit := big.NewInt(maxCanonicalBase)
+		if (*big.Int)(p.Base).Cmp(limit) < 0 {
+			limit = (*big.Int)(p.Base)
+		}
+		for base := big.NewInt(2); base.Cmp(limit) < 0; base.Add(base, big.NewInt(1)) {
+			invs, err := checkGen(context.Background(), N, p.A, base)
+			if err != nil {
+				continue
+			}
+			// the new proof is checked too, since the factors of A are only assumed to be prime
+			candidate := GeneralizedPocklingtonProof{A: p.A, Base: (*BigInt)(base), Inverses: invs}
+			if candidate.Check(N) == nil {
+				*p = candidate
+				break
+			}
+		}
+	}
+	slices.SortStableFunc(p.Inverses, compareInverses)
+	return nil
+}
+
+// sortEntries sorts the factorization of A by prime and the inverses by value.
+// It only reorders entries and never changes what the proof states, so the proof verifies as before.
+// It returns an error if a field the proof needs is missing.
+func (p *GeneralizedPocklingtonProof) sortEntries() error {
+	if p.missingField() {
+		return &PocklingtonError{Method: GeneralizedPocklingtonKey, Reason: "missing field"}
+	}
+	p.A.Canonicalize()
+	slices.SortStableFunc(p.Inverses, compareInverses)
+	return nil
+}
+
+func compareInverses(a, b Inverse) int {
+	return (*big.Int)(a.Value).Cmp((*big.Int)(b.Value))
+}
@@ -0,0 +1,155 @@
+package primality
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+var ErrNoRegistryFiles = errors.New("no registry files found")
+
+// Origins records the files the proofs and axioms of a loaded registry were read from.
+type Origins struct {
+	// Proofs[i] lists the files containing the i-th proof of the registry.
+	// Identical proofs in several files are loaded only once, so a proof may come from more than one file.
+	Proofs [][]string
+	// Axioms[i] lists the files containing the i-th axiom of the registry.
+	Axioms [][]string
+	// Numbers maps each number proven or assumed, in decimal, to every file with a proof or axiom of it.
+	Numbers map[string][]string
+}
+
+// ReadRegistryFile reads a registry from the JSON file name.
+func ReadRegistryFile(name string) (*Registry, error) {
+	dat, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return decodeRegistry(name, dat)
+}
+
+// LoadRegistry reads registries from name and combines them into one registry.
+// name may be a JSON file, a directory, in which case every *.json file in it is read,
+// or a pattern accepted by filepath.Glob.
+// Identical proofs and axioms found in more than one file are included only once.
+func LoadRegistry(name string) (*Registry, *Origins, error) {
+	if info, err := os.Stat(name); err == nil {
+		if info.IsDir() {
+			reg, origins, err := LoadRegistryFS(os.DirFS(name), "*.json")
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to load %s: %w", name, err)
+			}
+			origins.rename(func(file string) string {
+				return filepath.Join(name, filepath.FromSlash(file))
+			})
+			return reg, origins, nil
+		}
+		return loadRegistryFiles([]string{name}, os.ReadFile)
+	}
+	names, err := filepath.Glob(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNoRegistryFiles, name)
+	}
+	return loadRegistryFiles(names, os.ReadFile)
+}
+
+// LoadRegistryFS reads every file in fsys matching pattern, as in fs.Glob,
+// and combines them into one registry.
+// Identical proofs and axioms found in more than one file are included only once.
+func LoadRegistryFS(fsys fs.FS, pattern string) (*Registry, *Origins, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNoRegistryFiles, pattern)
+	}
+	return loadRegistryFiles(names, func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+func loadRegistryFiles(names []string, readFile func(name string) ([]byte, error)) (*Registry, *Origins, error) {
+	combined := &Registry{}
+	origins := &Origins{Numbers: map[string][]string{}}
+	// indices of proofs in combined by their JSON encodings
+	seen := map[string]int{}
+	for _, name := range names {
+		dat, err := readFile(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		reg, err := decodeRegistry(name, dat)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, proof := range reg.Proofs {
+			encoded, err := proof.marshalContent()
+			if err != nil {
+				return nil, nil, err
+			}
+			n := (*big.Int)(proof.N).String()
+			origins.Numbers[n] = appendFile(origins.Numbers[n], name)
+			i, ok := seen[string(encoded)]
+			if !ok {
+				i = len(combined.Proofs)
+				seen[string(encoded)] = i
+				combined.Proofs = append(combined.Proofs, proof)
+				origins.Proofs = append(origins.Proofs, nil)
+			}
+			origins.Proofs[i] = appendFile(origins.Proofs[i], name)
+		}
+		for _, axiom := range reg.Axioms {
+			n := (*big.Int)(axiom.N).String()
+			origins.Numbers[n] = appendFile(origins.Numbers[n], name)
+			i := slices.IndexFunc(combined.Axioms, func(a Axiom) bool { return jsonEqual(a, axiom) })
+			if i < 0 {
+				i = len(combined.Axioms)
+				combined.Axioms = append(combined.Axioms, axiom)
+				origins.Axioms = append(origins.Axioms, nil)
+			}
+			origins.Axioms[i] = appendFile(origins.Axioms[i], name)
+		}
+	}
+	return combined, origins, nil
+}
+
+func decodeRegistry(name string, dat []byte) (*Registry, error) {
+	var reg Registry
+	if err := json.Unmarshal(dat, &reg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", name, err)
+	}
+	return &reg, nil
+}
+
+// appendFile appends name to files unless it is already the last one.
+func appendFile(files []string, name string) []string {
+	if len(files) == 0 || files[len(files)-1] != name {
+		return append(files, name)
+	}
+	return files
+}
+
+// rename replaces every file name in o with f applied to it.
+func (o *Origins) rename(f func(string) string) {
+	for _, lists := range [][][]string{o.Proofs, o.Axioms} {
+		for _, files := range lists {
+			for i, file := range files {
+				files[i] = f(file)
+			}
+		}
+	}
+	for _, files := range o.Numbers {
+		for i, file := range files {
+			files[i] = f(file)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package primality
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRegistryFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"3.json": {Data: []byte(`{"proofs":[{"n":"2"},{"n":"3","generalized-pocklington":{"a":{"int":"2","factorization":[{"prime":"2","exponent":1}]},"base":"2","inverses":[{"mod":"3","value":"1","inv":"1"}]}}]}`)},
+		"5.json": {Data: []byte(`{"proofs":[{"n":"2"},{"n":"5","generalized-pocklington":{"a":{"int":"4","factorization":[{"prime":"2","exponent":2}]},"base":"2","inverses":[{"mod":"5","value":"3","inv":"3"}]}}]}`)},
+		"README": {Data: []byte(`not a registry`)},
+	}
+	reg, origins, err := LoadRegistryFS(fsys, "*.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, reg.Proofs, 3)
+	assert.Equal(t, &Origins{
+		Proofs: [][]string{{"3.json", "5.json"}, {"3.json"}, {"5.json"}},
+		Numbers: map[string][]string{
+			"2": {"3.json", "5.json"},
+			"3": {"3.json"},
+			"5": {"5.json"},
+		},
+	}, origins)
+
+	// the inverse in 5.json is incorrect
+	err = reg.Check()
+	var depErr *DependencyError
+	if assert.True(t, errors.As(err, &depErr)) {
+		assert.Equal(t, []string{"5.json"}, origins.Proofs[depErr.Proof])
+	}
+
+	_, _, err = LoadRegistryFS(fsys, "*.txt")
+	assert.ErrorIs(t, err, ErrNoRegistryFiles)
+}
+
+func TestLoadRegistryFSOriginOfFailedProof(t *testing.T) {
+	// both files prove 5, but only the proof in bad.json is incorrect
+	fsys := fstest.MapFS{
+		"bad.json":  {Data: []byte(`{"proofs":[{"n":"2"},{"n":"5","generalized-pocklington":{"a":{"int":"4","factorization":[{"prime":"2","exponent":2}]},"base":"2","inverses":[{"mod":"5","value":"3","inv":"3"}]}}]}`)},
+		"good.json": {Data: []byte(`{"proofs":[{"n":"2"},{"n":"5","generalized-pocklington":{"a":{"int":"4","factorization":[{"prime":"2","exponent":2}]},"base":"2","inverses":[{"mod":"5","value":"3","inv":"2"}]}}]}`)},
+	}
+	reg, origins, err := LoadRegistryFS(fsys, "*.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"bad.json", "good.json"}, origins.Numbers["5"])
+	err = reg.Check()
+	var depErr *DependencyError
+	if assert.True(t, errors.As(err, &depErr)) {
+		assert.Equal(t, []string{"bad.json"}, origins.Proofs[depErr.Proof])
+	}
+}
+
+func TestLoadRegistryDir(t *testing.T) {
+	reg, origins, err := LoadRegistry("../small")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, reg.Check())
+	assert.Contains(t, origins.Numbers["181"], "../small/181.json")
+}
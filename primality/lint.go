@@ -0,0 +1,131 @@
+package primality
+
+import (
+	"fmt"
+	"math/big"
+)
+
+type LintKind string
+
+const (
+	// LintDuplicate is reported when the same N is proven more than once.
+	LintDuplicate LintKind = "duplicate"
+	// LintOrphan is reported when no target depends on a proof, directly or indirectly.
+	LintOrphan LintKind = "orphan"
+	// LintOrder is reported when a proof appears before one of its dependencies.
+	LintOrder LintKind = "order"
+	// LintFactorOrder is reported when a factorization is not sorted by prime or has repeated primes.
+	LintFactorOrder LintKind = "factor-order"
+	// LintAxiom is reported when an axiom is proven in the registry or nothing depends on it.
+	LintAxiom LintKind = "axiom"
+)
+
+// LintIssue is a problem found by Registry.Lint.
+// Lint issues do not make a registry incorrect.
+type LintIssue struct {
+	Kind    LintKind
+	N       *big.Int
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Kind, i.N.String(), i.Message)
+}
+
+// IsCanonical reports whether the primes in the factorization are in strictly increasing order.
+func (f *FactoredInt) IsCanonical() bool {
+	for i := 1; i < len(f.Factorization); i++ {
+		if (*big.Int)(f.Factorization[i-1].Prime).Cmp((*big.Int)(f.Factorization[i].Prime)) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Lint reports issues in the registry that Check does not consider errors.
+// Orphan proofs are only reported if targets is not empty.
+// Lint does not check the correctness of the proofs.
+func (r *Registry) Lint(targets []*big.Int) []LintIssue {
+	issues := []LintIssue{}
+	firstIndex := map[string]int{}
+	for i, proof := range r.Proofs {
+		n := (*big.Int)(proof.N).String()
+		if j, ok := firstIndex[n]; ok {
+			issues = append(issues, LintIssue{
+				Kind:    LintDuplicate,
+				N:       (*big.Int)(proof.N),
+				Message: fmt.Sprintf("proofs #%d and #%d prove the same number", j, i),
+			})
+			continue
+		}
+		firstIndex[n] = i
+	}
+	for i, proof := range r.Proofs {
+		for _, d := range proof.Dep() {
+			if j, ok := firstIndex[d.String()]; ok && j > i {
+				issues = append(issues, LintIssue{
+					Kind:    LintOrder,
+					N:       (*big.Int)(proof.N),
+					Message: fmt.Sprintf("proof #%d appears before its dependency %s (#%d)", i, d.String(), j),
+				})
+			}
+		}
+		if method, ok := proof.Method.(*GeneralizedPocklingtonProof); ok && method.A != nil && !method.A.IsCanonical() {
+			issues = append(issues, LintIssue{
+				Kind:    LintFactorOrder,
+				N:       (*big.Int)(proof.N),
+				Message: "factorization of A is not sorted by prime",
+			})
+		}
+	}
+	used := map[string]struct{}{}
+	for _, axiom := range r.UsedAxioms() {
+		used[(*big.Int)(axiom.N).String()] = struct{}{}
+	}
+	for i, axiom := range r.Axioms {
+		n := (*big.Int)(axiom.N).String()
+		if _, ok := firstIndex[n]; ok {
+			issues = append(issues, LintIssue{
+				Kind:    LintAxiom,
+				N:       (*big.Int)(axiom.N),
+				Message: fmt.Sprintf("axiom #%d is proven by proof #%d", i, firstIndex[n]),
+			})
+		} else if _, ok := used[n]; !ok {
+			issues = append(issues, LintIssue{
+				Kind:    LintAxiom,
+				N:       (*big.Int)(axiom.N),
+				Message: fmt.Sprintf("axiom #%d is not used by any proof", i),
+			})
+		}
+	}
+	if len(targets) > 0 {
+		reachable := r.reachableFrom(targets)
+		for i, proof := range r.Proofs {
+			if _, ok := reachable[(*big.Int)(proof.N).String()]; !ok {
+				issues = append(issues, LintIssue{
+					Kind:    LintOrphan,
+					N:       (*big.Int)(proof.N),
+					Message: fmt.Sprintf("proof #%d is not needed by any target", i),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// reachableFrom returns the set of numbers that targets depend on, directly or indirectly, including targets themselves.
+func (r *Registry) reachableFrom(targets []*big.Int) map[string]struct{} {
+	deps := r.depMap()
+	reachable := map[string]struct{}{}
+	stack := append([]*big.Int{}, targets...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := reachable[n.String()]; ok {
+			continue
+		}
+		reachable[n.String()] = struct{}{}
+		stack = append(stack, deps[n.String()]...)
+	}
+	return reachable
+}
@@ -0,0 +1,49 @@
+package primality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryLint(t *testing.T) {
+	proof5, err := Prove(big.NewInt(5))
+	if !assert.NoError(t, err) {
+		return
+	}
+	proof17, err := Prove(big.NewInt(17))
+	if !assert.NoError(t, err) {
+		return
+	}
+	proof2 := Proof{N: (*BigInt)(big.NewInt(2))}
+	registry := &Registry{
+		Proofs: []Proof{*proof5, proof2, *proof17, proof2},
+	}
+	assert.NoError(t, registry.Check())
+	issues := registry.Lint([]*big.Int{big.NewInt(5)})
+	kinds := []LintKind{}
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	assert.Equal(t, []LintKind{LintDuplicate, LintOrder, LintOrphan}, kinds)
+	assert.Equal(t, big.NewInt(17), issues[2].N)
+
+	registry = &Registry{
+		Proofs: []Proof{proof2, *proof5, *proof17},
+	}
+	assert.Empty(t, registry.Lint(nil))
+}
+
+func TestFactoredIntIsCanonical(t *testing.T) {
+	a := FactoredInt{
+		Int: (*BigInt)(big.NewInt(45)),
+		Factorization: []FactorEntry{
+			{Prime: (*BigInt)(big.NewInt(5)), Exponent: 1},
+			{Prime: (*BigInt)(big.NewInt(3)), Exponent: 2},
+		},
+	}
+	assert.False(t, a.IsCanonical())
+	a.Factorization[0], a.Factorization[1] = a.Factorization[1], a.Factorization[0]
+	assert.True(t, a.IsCanonical())
+}
@@ -0,0 +1,79 @@
+package primality
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Merge returns a registry containing the proofs of all of regs.
+// If a number is proven more than once, the valid proof with the shortest JSON encoding, ignoring metadata, is kept.
+// Axioms are kept unless the number is proven; if an axiom is declared more than once, the first one is kept.
+// The result is verified with Check and its proofs are in topological order,
+// i.e., every proof appears after its dependencies.
+func Merge(regs ...*Registry) (*Registry, error) {
+	type candidate struct {
+		proof Proof
+		cost  int
+	}
+	best := map[string]*candidate{}
+	invalid := map[string]error{}
+	order := []string{}
+	for _, reg := range regs {
+		for _, proof := range reg.Proofs {
+			n := (*big.Int)(proof.N).String()
+			if _, ok := best[n]; !ok {
+				if _, ok := invalid[n]; !ok {
+					order = append(order, n)
+				}
+			}
+			if err := proof.Check(); err != nil {
+				if _, ok := invalid[n]; !ok {
+					invalid[n] = err
+				}
+				continue
+			}
+			encoded, err := proof.marshalContent()
+			if err != nil {
+				return nil, err
+			}
+			if c, ok := best[n]; !ok || len(encoded) < c.cost {
+				best[n] = &candidate{proof: proof, cost: len(encoded)}
+			}
+		}
+	}
+	merged := &Registry{
+		Proofs: make([]Proof, 0, len(best)),
+	}
+	for _, n := range order {
+		c, ok := best[n]
+		if !ok {
+			return nil, errors.Join(fmt.Errorf("no valid proof of %s", n), invalid[n])
+		}
+		merged.Proofs = append(merged.Proofs, c.proof)
+	}
+	seenAxioms := map[string]struct{}{}
+	for _, reg := range regs {
+		for _, axiom := range reg.Axioms {
+			n := (*big.Int)(axiom.N).String()
+			if _, ok := best[n]; ok {
+				continue
+			}
+			if _, ok := seenAxioms[n]; ok {
+				continue
+			}
+			seenAxioms[n] = struct{}{}
+			merged.Axioms = append(merged.Axioms, axiom)
+		}
+	}
+	sortAxioms(merged.Axioms)
+	sorted, err := topologicalOrder(merged.Proofs)
+	if err != nil {
+		return nil, err
+	}
+	merged.Proofs = sorted
+	if err := merged.Check(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
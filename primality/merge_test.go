@@ -0,0 +1,55 @@
+package primality
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	proofs := map[int64]Proof{}
+	for _, n := range []int64{2, 3, 5, 11} {
+		proof, err := Prove(big.NewInt(n))
+		if !assert.NoError(t, err) {
+			return
+		}
+		proofs[n] = *proof
+	}
+	// a valid but longer proof of 5 with base 13
+	longer := Proof{
+		N: (*BigInt)(big.NewInt(5)),
+		Method: &GeneralizedPocklingtonProof{
+			A: &FactoredInt{
+				Int: (*BigInt)(big.NewInt(4)),
+				Factorization: []FactorEntry{
+					{Prime: (*BigInt)(big.NewInt(2)), Exponent: 2},
+				},
+			},
+			Base: (*BigInt)(big.NewInt(13)),
+			Inverses: []Inverse{
+				{
+					Mod:   (*BigInt)(big.NewInt(5)),
+					Value: (*BigInt)(big.NewInt(3)),
+					Inv:   (*BigInt)(big.NewInt(2)),
+				},
+			},
+		},
+	}
+	assert.NoError(t, longer.Check())
+	reg1 := &Registry{Proofs: []Proof{proofs[11], longer}}
+	reg2 := &Registry{Proofs: []Proof{proofs[5], proofs[3], proofs[2], proofs[5]}}
+	merged, err := Merge(reg1, reg2)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Proof{proofs[2], proofs[3], proofs[5], proofs[11]}, merged.Proofs)
+	}
+
+	_, err = Merge(reg1)
+	assert.ErrorIs(t, err, ErrMissingDependency)
+}
+
+func TestMergeInvalid(t *testing.T) {
+	invalid := Proof{N: (*BigInt)(big.NewInt(5))}
+	_, err := Merge(&Registry{Proofs: []Proof{invalid}})
+	assert.ErrorIs(t, err, ErrNoProof)
+}
@@ -0,0 +1,211 @@
+package primality
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrNotIncluded = errors.New("proof is not included")
+
+// ID returns the content hash of the proof: the hex-encoded SHA-256 hash of the JSON encoding
+// of its canonical form (see Registry.Canonicalize), without metadata.
+// Valid proofs that differ only in the order of factors or inverses, the choice of base, or metadata
+// have the same ID.
+func (p *Proof) ID() (string, error) {
+	canonical, err := p.canonicalCopy()
+	if err != nil {
+		return "", err
+	}
+	encoded, err := canonical.marshalContent()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// MerkleRoot returns the hex-encoded root of the Merkle tree whose leaves are the IDs of the proofs
+// in the canonical form of the registry, in order.
+// The tree is built as in RFC 6962 with SHA-256. Axioms and metadata are not covered.
+func (r *Registry) MerkleRoot() (string, error) {
+	canonical, err := r.canonicalCopy()
+	if err != nil {
+		return "", err
+	}
+	leaves, _, err := canonical.merkleLeaves()
+	if err != nil {
+		return "", err
+	}
+	root := merkleTreeHash(leaves)
+	return hex.EncodeToString(root), nil
+}
+
+// InclusionProof shows that the proof with a given ID is a leaf of the Merkle tree of a registry.
+type InclusionProof struct {
+	N  *BigInt `json:"n"`
+	ID string  `json:"id"`
+	// Index is the position of the leaf and Size is the number of leaves.
+	Index int `json:"index"`
+	Size  int `json:"size"`
+	// Path is the list of hex-encoded hashes of the sibling subtrees, from the leaf to the root.
+	Path []string `json:"path"`
+}
+
+// InclusionProof returns a proof that the proof of n is included in the Merkle tree of the registry.
+// It returns an error wrapping ErrNotIncluded if n is not proven in the registry.
+func (r *Registry) InclusionProof(n *big.Int) (*InclusionProof, error) {
+	canonical, err := r.canonicalCopy()
+	if err != nil {
+		return nil, err
+	}
+	leaves, ids, err := canonical.merkleLeaves()
+	if err != nil {
+		return nil, err
+	}
+	for i, proof := range canonical.Proofs {
+		if (*big.Int)(proof.N).Cmp(n) != 0 {
+			continue
+		}
+		path := merkleAuditPath(i, leaves)
+		pathHex := make([]string, len(path))
+		for j, hash := range path {
+			pathHex[j] = hex.EncodeToString(hash)
+		}
+		return &InclusionProof{
+			N:     proof.N,
+			ID:    ids[i],
+			Index: i,
+			Size:  len(leaves),
+			Path:  pathHex,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotIncluded, n.String())
+}
+
+// VerifyInclusion checks that proof is included, as shown by inclusion, in the Merkle tree with the hex-encoded root.
+// It does not check the proof itself; use Proof.Check for that.
+func VerifyInclusion(root string, proof *Proof, inclusion *InclusionProof) error {
+	id, err := proof.ID()
+	if err != nil {
+		return err
+	}
+	if id != inclusion.ID || (*big.Int)(proof.N).Cmp((*big.Int)(inclusion.N)) != 0 {
+		return fmt.Errorf("%w: the inclusion proof is for another proof", ErrNotIncluded)
+	}
+	if inclusion.Index < 0 || inclusion.Index >= inclusion.Size {
+		return fmt.Errorf("%w: index out of range", ErrNotIncluded)
+	}
+	leaf, err := hex.DecodeString(id)
+	if err != nil {
+		return err
+	}
+	path := make([][]byte, len(inclusion.Path))
+	for i, hash := range inclusion.Path {
+		if path[i], err = hex.DecodeString(hash); err != nil {
+			return err
+		}
+	}
+	rootHash, err := hex.DecodeString(root)
+	if err != nil {
+		return err
+	}
+	computed, ok := merkleRootFromPath(inclusion.Index, inclusion.Size, merkleLeafHash(leaf), path)
+	if !ok || !bytes.Equal(computed, rootHash) {
+		return fmt.Errorf("%w: root mismatch", ErrNotIncluded)
+	}
+	return nil
+}
+
+// merkleLeaves returns the leaf hashes of the Merkle tree of the registry and the IDs they are computed from.
+// The registry must be in canonical form.
+func (r *Registry) merkleLeaves() ([][]byte, []string, error) {
+	leaves := make([][]byte, len(r.Proofs))
+	ids := make([]string, len(r.Proofs))
+	for i, proof := range r.Proofs {
+		id, err := proof.ID()
+		if err != nil {
+			return nil, nil, err
+		}
+		leaf, err := hex.DecodeString(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		leaves[i] = merkleLeafHash(leaf)
+		ids[i] = id
+	}
+	return leaves, ids, nil
+}
+
+func merkleLeafHash(data []byte) []byte {
+	hash := sha256.Sum256(append([]byte{0}, data...))
+	return hash[:]
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	data := append([]byte{1}, left...)
+	data = append(data, right...)
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// merkleSplit returns the largest power of two smaller than n, for n >= 2.
+func merkleSplit(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+// merkleTreeHash computes MTH of RFC 6962 from leaf hashes.
+func merkleTreeHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		hash := sha256.Sum256(nil)
+		return hash[:]
+	case 1:
+		return leaves[0]
+	}
+	k := merkleSplit(len(leaves))
+	return merkleNodeHash(merkleTreeHash(leaves[:k]), merkleTreeHash(leaves[k:]))
+}
+
+// merkleAuditPath computes PATH(m, D[n]) of RFC 6962 from leaf hashes.
+func merkleAuditPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return [][]byte{}
+	}
+	k := merkleSplit(len(leaves))
+	if m < k {
+		return append(merkleAuditPath(m, leaves[:k]), merkleTreeHash(leaves[k:]))
+	}
+	return append(merkleAuditPath(m-k, leaves[k:]), merkleTreeHash(leaves[:k]))
+}
+
+// merkleRootFromPath computes the root from a leaf hash and its audit path,
+// following the verification algorithm of RFC 9162, section 2.1.3.2.
+func merkleRootFromPath(index, size int, leaf []byte, path [][]byte) ([]byte, bool) {
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range path {
+		if sn == 0 {
+			return nil, false
+		}
+		if fn%2 == 1 || fn == sn {
+			r = merkleNodeHash(p, r)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	return r, sn == 0
+}
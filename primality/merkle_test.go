@@ -0,0 +1,94 @@
+package primality
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofID(t *testing.T) {
+	proof, err := Prove(big.NewInt(181))
+	if !assert.NoError(t, err) {
+		return
+	}
+	id, err := proof.ID()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, id, 64)
+	// reordering the factors of A does not change the ID
+	reordered := Proof{
+		N: proof.N,
+		Method: &GeneralizedPocklingtonProof{
+			A: &FactoredInt{
+				Int: (*BigInt)(big.NewInt(36)),
+				Factorization: []FactorEntry{
+					{Prime: (*BigInt)(big.NewInt(3)), Exponent: 2},
+					{Prime: (*BigInt)(big.NewInt(2)), Exponent: 2},
+				},
+			},
+			Base:     proof.Method.(*GeneralizedPocklingtonProof).Base,
+			Inverses: proof.Method.(*GeneralizedPocklingtonProof).Inverses,
+		},
+		Metadata: &Metadata{CurveName: "TestCurve"},
+	}
+	assert.NoError(t, reordered.Check())
+	reorderedID, err := reordered.ID()
+	if assert.NoError(t, err) {
+		assert.Equal(t, id, reorderedID)
+	}
+	// the proof itself is not modified
+	assert.Equal(t, big.NewInt(3), (*big.Int)(reordered.Method.(*GeneralizedPocklingtonProof).A.Factorization[0].Prime))
+}
+
+func TestMerkleInclusion(t *testing.T) {
+	registry := &Registry{}
+	for n := int64(2); n < 40; n++ {
+		proof, err := Prove(big.NewInt(n))
+		if err != nil {
+			continue
+		}
+		registry.Proofs = append(registry.Proofs, *proof)
+		root, err := registry.MerkleRoot()
+		if !assert.NoError(t, err) {
+			return
+		}
+		// every proof in registries of every size is included
+		for _, p := range registry.Proofs {
+			inclusion, err := registry.InclusionProof((*big.Int)(p.N))
+			if assert.NoError(t, err) {
+				assert.NoError(t, VerifyInclusion(root, &p, inclusion), "n = %s, size = %d", (*big.Int)(p.N).String(), len(registry.Proofs))
+			}
+		}
+	}
+	root, err := registry.MerkleRoot()
+	if !assert.NoError(t, err) {
+		return
+	}
+	inclusion, err := registry.InclusionProof(big.NewInt(13))
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := Prove(big.NewInt(17))
+	if assert.NoError(t, err) {
+		assert.ErrorIs(t, VerifyInclusion(root, other, inclusion), ErrNotIncluded)
+	}
+	proof13, err := Prove(big.NewInt(13))
+	if assert.NoError(t, err) {
+		wrongRoot := hex.EncodeToString(make([]byte, 32))
+		assert.ErrorIs(t, VerifyInclusion(wrongRoot, proof13, inclusion), ErrNotIncluded)
+	}
+	_, err = registry.InclusionProof(big.NewInt(41))
+	assert.ErrorIs(t, err, ErrNotIncluded)
+}
+
+func TestMerkleTreeHash(t *testing.T) {
+	a := merkleLeafHash([]byte("a"))
+	b := merkleLeafHash([]byte("b"))
+	c := merkleLeafHash([]byte("c"))
+	assert.Equal(t, a, merkleTreeHash([][]byte{a}))
+	assert.Equal(t, merkleNodeHash(merkleNodeHash(a, b), c), merkleTreeHash([][]byte{a, b, c}))
+	assert.Equal(t, [][]byte{merkleNodeHash(a, b)}, merkleAuditPath(2, [][]byte{a, b, c}))
+}
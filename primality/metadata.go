@@ -0,0 +1,62 @@
+package primality
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime/debug"
+	"time"
+)
+
+const modulePath = "github.com/koba-e964/crypto-primality-proof"
+
+// Metadata records where a registry or a proof came from.
+// It is informational only; Registry.Check ignores it.
+type Metadata struct {
+	// SourceURL is the URL the registry or proof was read from.
+	SourceURL string `json:"source-url,omitempty"`
+	// CurveName is the name of the curve the registry or proof was made for.
+	CurveName string `json:"curve-name,omitempty"`
+	// FetchedAt is when SourceURL was read.
+	// It changes every time the source is read again, so it is left out of proof IDs and signatures (see Signature).
+	FetchedAt *time.Time `json:"fetched-at,omitempty"`
+	// Generator is the tool and its version that made the registry or proof, as returned by GeneratorVersion.
+	Generator string `json:"generator,omitempty"`
+	// SourceHash is the hash of the raw content read from SourceURL, as returned by HashSource.
+	SourceHash string `json:"source-hash,omitempty"`
+}
+
+// withoutVolatile returns m without FetchedAt, or nil if nothing else is left.
+func (m *Metadata) withoutVolatile() *Metadata {
+	if m == nil {
+		return nil
+	}
+	result := *m
+	result.FetchedAt = nil
+	if result == (Metadata{}) {
+		return nil
+	}
+	return &result
+}
+
+// GeneratorVersion returns the module path and the version of this module in the running binary,
+// e.g. "github.com/koba-e964/crypto-primality-proof@v1.2.3".
+func GeneratorVersion() string {
+	version := "(unknown)"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath {
+			version = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+			}
+		}
+	}
+	return modulePath + "@" + version
+}
+
+// HashSource returns the hash of raw source content in the form "sha256:<hex>".
+func HashSource(content []byte) string {
+	hash := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
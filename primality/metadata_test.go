@@ -0,0 +1,102 @@
+package primality
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofMetadata(t *testing.T) {
+	proof, err := Prove(big.NewInt(257))
+	if !assert.NoError(t, err) {
+		return
+	}
+	fetchedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	withMetadata := *proof
+	withMetadata.Metadata = &Metadata{
+		SourceURL:  "https://safecurves.cr.yp.to/proof/257.html",
+		FetchedAt:  &fetchedAt,
+		SourceHash: HashSource([]byte("page")),
+	}
+	assert.NoError(t, withMetadata.Check())
+
+	encoded, err := json.Marshal(withMetadata)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(encoded), `"metadata":{"source-url":"https://safecurves.cr.yp.to/proof/257.html","fetched-at":"2024-03-01T12:00:00Z","source-hash":"sha256:`)
+	var decoded Proof
+	if assert.NoError(t, json.Unmarshal(encoded, &decoded)) {
+		assert.Equal(t, withMetadata.Metadata, decoded.Metadata)
+	}
+
+	// metadata does not affect the identity of the proof
+	key1, err := cacheKey(proof)
+	assert.NoError(t, err)
+	key2, err := cacheKey(&withMetadata)
+	assert.NoError(t, err)
+	assert.Equal(t, key1, key2)
+}
+
+func TestGeneratorVersion(t *testing.T) {
+	assert.True(t, strings.HasPrefix(GeneratorVersion(), "github.com/koba-e964/crypto-primality-proof@"))
+}
+
+func TestCanonicalKeepsFetchedAt(t *testing.T) {
+	proof, err := Prove(big.NewInt(257))
+	if !assert.NoError(t, err) {
+		return
+	}
+	fetched := func(at time.Time) *Registry {
+		withMetadata := *proof
+		withMetadata.Metadata = &Metadata{FetchedAt: &at, SourceHash: HashSource([]byte("page"))}
+		return &Registry{
+			Proofs:   []Proof{{N: (*BigInt)(big.NewInt(2)), Metadata: &Metadata{FetchedAt: &at}}, withMetadata},
+			Metadata: &Metadata{SourceURL: "https://safecurves.cr.yp.to/primeproofs.html", FetchedAt: &at},
+		}
+	}
+	fetchedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	reg1 := fetched(fetchedAt)
+	reg2 := fetched(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	encoded1, err := reg1.MarshalCanonical()
+	if !assert.NoError(t, err) {
+		return
+	}
+	encoded2, err := reg2.MarshalCanonical()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, string(encoded1), string(encoded2))
+	assert.Contains(t, string(encoded1), `"fetched-at": "2024-03-01T12:00:00Z"`)
+
+	// a merged registry still records when its proofs were fetched
+	merged, err := Merge(reg1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	encoded, err := merged.MarshalCanonical()
+	if !assert.NoError(t, err) {
+		return
+	}
+	var decoded Registry
+	if assert.NoError(t, json.Unmarshal(encoded, &decoded)) && assert.NotNil(t, decoded.Proofs[1].Metadata) {
+		assert.Equal(t, &fetchedAt, decoded.Proofs[1].Metadata.FetchedAt)
+	}
+
+	// fetching the same source again yields the same IDs and signatures
+	id1, err := reg1.Proofs[1].ID()
+	assert.NoError(t, err)
+	id2, err := reg2.Proofs[1].ID()
+	assert.NoError(t, err)
+	assert.Equal(t, id1, id2)
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	sig, err := SignRegistry(reg1, key)
+	if assert.NoError(t, err) {
+		assert.NoError(t, VerifyRegistrySignature(reg2, sig, key.Public().(ed25519.PublicKey)))
+	}
+}
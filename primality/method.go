@@ -0,0 +1,61 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"sync"
+)
+
+// ProofMethod is a method of proving that a number N is prime.
+//
+// A ProofMethod is marshalled to JSON as the value of the key returned by Key,
+// next to "n" in the proof object. Use RegisterProofMethod to make a method known to Proof.UnmarshalJSON.
+// Proof.UnmarshalJSON rejects a proof object with a key that is neither "n", "metadata" nor a registered method,
+// or with more than one method. The value of the method must be a non-empty JSON object,
+// so a method must marshal to at least one field.
+//
+// If a ProofMethod also has a method CheckContext(ctx context.Context, N *big.Int) error,
+// Proof.CheckContext calls it instead of Check.
+type ProofMethod interface {
+	// Key returns the JSON key of the method, e.g. "generalized-pocklington".
+	Key() string
+	// Check checks that the proof shows N is prime, assuming its dependencies are prime.
+	Check(N *big.Int) error
+	// Dep returns the numbers whose primality the proof relies on.
+	Dep() []*big.Int
+	// DepsSmallerThanN reports whether every dependency must be strictly smaller than N.
+	// Methods that return false may depend on larger numbers and rely on Registry.Check to reject cycles.
+	DepsSmallerThanN() bool
+}
+
+type contextChecker interface {
+	CheckContext(ctx context.Context, N *big.Int) error
+}
+
+var (
+	proofMethodsMu sync.RWMutex
+	proofMethods   = map[string]func() ProofMethod{}
+)
+
+// RegisterProofMethod registers a proof method under the JSON key key.
+// newMethod must return a pointer to a new zero value which JSON is unmarshalled into.
+// It is only called for a non-empty JSON object; other values of key are rejected (see ProofMethod).
+// It panics if key is already registered or is "n" or "metadata".
+// It is intended to be called from init functions.
+func RegisterProofMethod(key string, newMethod func() ProofMethod) {
+	proofMethodsMu.Lock()
+	defer proofMethodsMu.Unlock()
+	if key == "n" || key == "metadata" {
+		panic("primality: invalid proof method key: " + key)
+	}
+	if _, ok := proofMethods[key]; ok {
+		panic("primality: proof method registered twice: " + key)
+	}
+	proofMethods[key] = newMethod
+}
+
+func lookupProofMethod(key string) func() ProofMethod {
+	proofMethodsMu.RLock()
+	defer proofMethodsMu.RUnlock()
+	return proofMethods[key]
+}
@@ -0,0 +1,81 @@
+package primality
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mutualProof is a bogus proof method whose dependencies may be larger than N.
+type mutualProof struct {
+	Other *BigInt `json:"other"`
+}
+
+func (p *mutualProof) Key() string {
+	return "test-mutual"
+}
+
+func (p *mutualProof) Check(N *big.Int) error {
+	return nil
+}
+
+func (p *mutualProof) Dep() []*big.Int {
+	return []*big.Int{(*big.Int)(p.Other)}
+}
+
+func (p *mutualProof) DepsSmallerThanN() bool {
+	return false
+}
+
+func init() {
+	RegisterProofMethod("test-mutual", func() ProofMethod {
+		return &mutualProof{}
+	})
+}
+
+func TestProofMethodRoundTrip(t *testing.T) {
+	data := []byte(`{"n":"5","test-mutual":{"other":"7"}}`)
+	var proof Proof
+	if assert.NoError(t, json.Unmarshal(data, &proof)) {
+		assert.Equal(t, &mutualProof{Other: (*BigInt)(big.NewInt(7))}, proof.Method)
+		encoded, err := json.Marshal(proof)
+		if assert.NoError(t, err) {
+			assert.Equal(t, data, encoded)
+		}
+	}
+}
+
+func TestProofUnmarshalInvalidMethod(t *testing.T) {
+	var proof Proof
+	assert.EqualError(t, json.Unmarshal([]byte(`{"n":"5","unknown":{}}`), &proof), `unknown proof method: "unknown"`)
+	assert.Error(t, json.Unmarshal([]byte(`{"n":"5","test-mutual":{"other":"7"},"generalized-pocklington":{}}`), &proof))
+}
+
+func TestRegistryCheckRejectsCycle(t *testing.T) {
+	mutual := func(n, other int64) Proof {
+		return Proof{
+			N:      (*BigInt)(big.NewInt(n)),
+			Method: &mutualProof{Other: (*BigInt)(big.NewInt(other))},
+		}
+	}
+	registry := &Registry{
+		Proofs: []Proof{mutual(5, 7), mutual(7, 5)},
+	}
+	err := registry.Check()
+	assert.ErrorIs(t, err, ErrCyclicDependency)
+	var cycleErr *CycleError
+	if assert.True(t, errors.As(err, &cycleErr)) {
+		assert.Equal(t, []*big.Int{big.NewInt(5), big.NewInt(7)}, cycleErr.Cycle)
+	}
+}
+
+func TestRegisterProofMethodTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterProofMethod(GeneralizedPocklingtonKey, func() ProofMethod {
+			return &GeneralizedPocklingtonProof{}
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+)
+
+const defaultPMinus1B1 = 100000
+
+// PMinus1Options configures PollardPMinus1. A nil *PMinus1Options uses the defaults.
+type PMinus1Options struct {
+	// B1 is the stage 1 bound, at most MaxB1. 0 means 100000.
+	B1 uint64
+	// B2 is the stage 2 bound, at most MaxB2. 0 means 100 * B1, but at most MaxB2. Stage 2 is skipped if B2 <= B1.
+	B2 uint64
+}
+
+func (o *PMinus1Options) b1() uint64 {
+	if o == nil || o.B1 == 0 {
+		return defaultPMinus1B1
+	}
+	return o.B1
+}
+
+func (o *PMinus1Options) b2() uint64 {
+	if o == nil || o.B2 == 0 {
+		return defaultB2(o.b1())
+	}
+	return o.B2
+}
+
+// PollardPMinus1 finds a nontrivial factor of the composite n with Pollard's p-1 method,
+// which finds a prime factor p if p-1 is the product of primes not exceeding B1 and at most one prime not exceeding B2.
+// It returns ErrNoFactorFound if it gives up, and an error wrapping ErrBoundTooLarge if B1 or B2 exceeds its maximum.
+func PollardPMinus1(ctx context.Context, n *big.Int, opts *PMinus1Options) (*big.Int, error) {
+	b1, b2 := opts.b1(), opts.b2()
+	if err := checkBounds(b1, b2); err != nil {
+		return nil, err
+	}
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	a := big.NewInt(3)
+	err := forPrimePowers(ctx, b1, func(k uint64) {
+		a.Exp(a, new(big.Int).SetUint64(k), n)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if factor := factorFromDiff(n, a, big.NewInt(1)); factor != nil {
+		return factor, nil
+	}
+	if b2 <= b1 {
+		return nil, ErrNoFactorFound
+	}
+	// a^s = 1 iff a^s + a^-s = 2 modulo p, so stage 2 runs on the Lucas sequence of v = a + a^-1.
+	inv := new(big.Int).ModInverse(a, n)
+	if inv == nil {
+		if factor := factorFromDiff(n, a, new(big.Int)); factor != nil {
+			return factor, nil
+		}
+		return nil, ErrNoFactorFound
+	}
+	v := inv.Add(inv, a)
+	v.Mod(v, n)
+	steps := stage2Steps(b1, b2)
+	if len(steps) == 0 {
+		return nil, ErrNoFactorFound
+	}
+	factor, err := lucasStage2(ctx, n, v, steps)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, ErrNoFactorFound
+	}
+	return factor, nil
+}
@@ -0,0 +1,56 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smoothPrime returns a prime p such that p - sign is the product of primes below 50, small k and large.
+func smoothPrime(large uint64, sign int64) *big.Int {
+	m := new(big.Int).SetUint64(large)
+	for _, p := range primesBelow(50) {
+		m.Mul(m, new(big.Int).SetUint64(p))
+	}
+	for k := int64(1); ; k++ {
+		p := new(big.Int).Mul(m, big.NewInt(k))
+		p.Add(p, big.NewInt(sign))
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+func smoothTestInt(large uint64, sign int64) (*big.Int, *big.Int) {
+	p := smoothPrime(large, sign)
+	q := nextPrime(new(big.Int).Lsh(big.NewInt(1), 200))
+	return new(big.Int).Mul(p, q), p
+}
+
+func TestPollardPMinus1(t *testing.T) {
+	n, p := smoothTestInt(1, 1)
+	d, err := PollardPMinus1(context.Background(), n, &PMinus1Options{B1: 1000, B2: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, p, d)
+}
+
+func TestPollardPMinus1BoundTooLarge(t *testing.T) {
+	n, _ := smoothTestInt(1, 1)
+	_, err := PollardPMinus1(context.Background(), n, &PMinus1Options{B1: 1 << 63})
+	assert.ErrorIs(t, err, ErrBoundTooLarge)
+	_, err = PollardPMinus1(context.Background(), n, &PMinus1Options{B1: 10, B2: MaxB2 + 1})
+	assert.ErrorIs(t, err, ErrBoundTooLarge)
+	assert.Equal(t, uint64(MaxB2), (&PMinus1Options{B1: 1 << 63}).b2())
+}
+
+func TestPollardPMinus1Stage2(t *testing.T) {
+	// 500009 is a prime
+	n, p := smoothTestInt(500009, 1)
+	_, err := PollardPMinus1(context.Background(), n, &PMinus1Options{B1: 1000, B2: 1000})
+	assert.ErrorIs(t, err, ErrNoFactorFound)
+	d, err := PollardPMinus1(context.Background(), n, &PMinus1Options{B1: 1000, B2: 1000000})
+	assert.NoError(t, err)
+	assert.Equal(t, p, d)
+}
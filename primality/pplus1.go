@@ -0,0 +1,235 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+)
+
+const (
+	defaultPPlus1B1     = 50000
+	defaultPPlus1Starts = 2
+)
+
+// PPlus1Options configures WilliamsPPlus1. A nil *PPlus1Options uses the defaults.
+type PPlus1Options struct {
+	// B1 is the stage 1 bound, at most MaxB1. 0 means 50000.
+	B1 uint64
+	// B2 is the stage 2 bound, at most MaxB2. 0 means 100 * B1, but at most MaxB2. Stage 2 is skipped if B2 <= B1.
+	B2 uint64
+	// Starts is the number of starting values tried. 0 means 2.
+	// The method only works for a prime factor p if the starting value gives a quadratic non-residue modulo p,
+	// which happens for about half of the starting values.
+	Starts int
+}
+
+func (o *PPlus1Options) b1() uint64 {
+	if o == nil || o.B1 == 0 {
+		return defaultPPlus1B1
+	}
+	return o.B1
+}
+
+func (o *PPlus1Options) b2() uint64 {
+	if o == nil || o.B2 == 0 {
+		return defaultB2(o.b1())
+	}
+	return o.B2
+}
+
+func (o *PPlus1Options) starts() int {
+	if o == nil || o.Starts == 0 {
+		return defaultPPlus1Starts
+	}
+	return o.Starts
+}
+
+// WilliamsPPlus1 finds a nontrivial factor of the composite n with Williams' p+1 method,
+// which finds a prime factor p if p+1 is the product of primes not exceeding B1 and at most one prime not exceeding B2.
+// It returns ErrNoFactorFound if it gives up, and an error wrapping ErrBoundTooLarge if B1 or B2 exceeds its maximum.
+func WilliamsPPlus1(ctx context.Context, n *big.Int, opts *PPlus1Options) (*big.Int, error) {
+	b1, b2 := opts.b1(), opts.b2()
+	if err := checkBounds(b1, b2); err != nil {
+		return nil, err
+	}
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	var steps []stage2Step
+	if b2 > b1 {
+		steps = stage2Steps(b1, b2)
+	}
+	for i := 0; i < opts.starts(); i++ {
+		v, factor := pPlus1Start(n, i)
+		if factor != nil {
+			return factor, nil
+		}
+		if err := lucasStage1(ctx, n, v, b1); err != nil {
+			return nil, err
+		}
+		if factor := factorFromDiff(n, v, big.NewInt(2)); factor != nil {
+			return factor, nil
+		}
+		if len(steps) > 0 {
+			factor, err := lucasStage2(ctx, n, v, steps)
+			if err != nil {
+				return nil, err
+			}
+			if factor != nil {
+				return factor, nil
+			}
+		}
+	}
+	return nil, ErrNoFactorFound
+}
+
+// pPlus1Start returns the i-th starting value of the p+1 method: 2/7, 6/5, then 3, 4, 5, ...
+// If an inversion modulo n fails, it returns the factor found instead.
+func pPlus1Start(n *big.Int, i int) (*big.Int, *big.Int) {
+	num, den := int64(i+1), int64(1)
+	switch i {
+	case 0:
+		num, den = 2, 7
+	case 1:
+		num, den = 6, 5
+	}
+	bden := big.NewInt(den)
+	inv := new(big.Int).ModInverse(bden, n)
+	if inv == nil {
+		return nil, new(big.Int).GCD(nil, nil, bden, n)
+	}
+	v := inv.Mul(inv, big.NewInt(num))
+	return v.Mod(v, n), nil
+}
+
+// lucasV returns V_k(v) modulo n, where V_0 = 2, V_1 = v and V_{i+1} = v V_i - V_{i-1}. k must be positive.
+func lucasV(v *big.Int, k uint64, n *big.Int) *big.Int {
+	// (x, y) = (V_m, V_{m+1}) for the prefix m of k read so far
+	x := new(big.Int).Set(v)
+	y := new(big.Int).Mul(v, v)
+	y.Sub(y, big.NewInt(2))
+	y.Mod(y, n)
+	t := new(big.Int)
+	top := 63
+	for k>>top == 0 {
+		top--
+	}
+	for i := top - 1; i >= 0; i-- {
+		// V_{2m+1} = V_m V_{m+1} - v
+		t.Mul(x, y)
+		t.Sub(t, v)
+		t.Mod(t, n)
+		if k>>i&1 == 1 {
+			// (V_{2m+1}, V_{2m+2})
+			x.Set(t)
+			y.Mul(y, y)
+			y.Sub(y, big.NewInt(2))
+			y.Mod(y, n)
+		} else {
+			// (V_{2m}, V_{2m+1})
+			x.Mul(x, x)
+			x.Sub(x, big.NewInt(2))
+			x.Mod(x, n)
+			y.Set(t)
+		}
+	}
+	return x
+}
+
+// lucasStage1 sets v = V_E(v) modulo n, where E is the product of the prime powers not exceeding b1.
+func lucasStage1(ctx context.Context, n, v *big.Int, b1 uint64) error {
+	return forPrimePowers(ctx, b1, func(k uint64) {
+		v.Set(lucasV(v, k, n))
+	})
+}
+
+// lucasStage2 looks for a prime s in (B1, B2] with V_s(v) = 2 modulo a prime factor of n.
+// Since V_{kD} - V_j = 0 modulo p iff k D = ±j modulo the order of the underlying group element,
+// a single product covers both k D - j and k D + j.
+func lucasStage2(ctx context.Context, n, v *big.Int, steps []stage2Step) (*big.Int, error) {
+	mulMod := func(z, x, y *big.Int) {
+		z.Mul(x, y)
+		z.Mod(z, n)
+	}
+	// baby[i] = V_j for j = stage2BabySteps[i], computed with V_{j+2} = V_j V_2 - V_{j-2}
+	baby := make([]*big.Int, len(stage2BabySteps))
+	v2 := lucasV(v, 2, n)
+	prev, cur := new(big.Int).Set(v), new(big.Int).Set(v) // V_{-1} = V_1
+	for j, i := uint64(1), 0; i < len(stage2BabySteps); j += 2 {
+		if j > 1 {
+			next := new(big.Int)
+			mulMod(next, cur, v2)
+			next.Sub(next, prev)
+			next.Mod(next, n)
+			prev, cur = cur, next
+		}
+		if stage2BabySteps[i] == j {
+			baby[i] = cur
+			i++
+		}
+	}
+	// giant = V_{kD}, computed with V_{(k+1)D} = V_{kD} V_D - V_{(k-1)D}
+	vd := lucasV(v, stage2D, n)
+	k := steps[0].k
+	giant := lucasV(v, k*stage2D, n)
+	prevGiant := big.NewInt(2)
+	if k > 1 {
+		prevGiant = lucasV(v, (k-1)*stage2D, n)
+	}
+	acc := big.NewInt(1)
+	t := new(big.Int)
+	for _, s := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for k < s.k {
+			next := new(big.Int)
+			mulMod(next, giant, vd)
+			next.Sub(next, prevGiant)
+			next.Mod(next, n)
+			prevGiant, giant = giant, next
+			k++
+		}
+		for _, i := range s.indices {
+			t.Sub(giant, baby[i])
+			mulMod(acc, acc, t)
+		}
+	}
+	return factorFromDiff(n, acc, new(big.Int)), nil
+}
+
+// factorFromDiff returns gcd(x - y, n) if it is a nontrivial factor of n, and nil otherwise.
+func factorFromDiff(n, x, y *big.Int) *big.Int {
+	d := new(big.Int).Sub(x, y)
+	g := d.GCD(nil, nil, d.Abs(d), n)
+	if g.Cmp(big.NewInt(1)) == 0 || g.Cmp(n) == 0 {
+		return nil
+	}
+	return g
+}
+
+// forPrimePowers calls f with products of the largest powers not exceeding b1 of the primes not exceeding b1,
+// which together multiply to the least common multiple of 1, ..., b1.
+// Each product fits in a uint64, and f is called as few times as possible.
+func forPrimePowers(ctx context.Context, b1 uint64, f func(k uint64)) error {
+	k := uint64(1)
+	for i, p := range primesBelow(b1 + 1) {
+		if i%pollInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		pe := p
+		for pe <= b1/p {
+			pe *= p
+		}
+		if k > (1<<64-1)/pe {
+			f(k)
+			k = 1
+		}
+		k *= pe
+	}
+	if k > 1 {
+		f(k)
+	}
+	return nil
+}
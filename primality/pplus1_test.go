@@ -0,0 +1,48 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWilliamsPPlus1(t *testing.T) {
+	n, p := smoothTestInt(1, -1)
+	d, err := WilliamsPPlus1(context.Background(), n, &PPlus1Options{B1: 1000, B2: 1000, Starts: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, p, d)
+}
+
+func TestWilliamsPPlus1Stage2(t *testing.T) {
+	n, p := smoothTestInt(500009, -1)
+	_, err := WilliamsPPlus1(context.Background(), n, &PPlus1Options{B1: 1000, B2: 1000, Starts: 4})
+	assert.ErrorIs(t, err, ErrNoFactorFound)
+	d, err := WilliamsPPlus1(context.Background(), n, &PPlus1Options{B1: 1000, B2: 1000000, Starts: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, p, d)
+}
+
+func TestWilliamsPPlus1BoundTooLarge(t *testing.T) {
+	n, _ := smoothTestInt(1, 1)
+	_, err := WilliamsPPlus1(context.Background(), n, &PPlus1Options{B1: 1 << 63})
+	assert.ErrorIs(t, err, ErrBoundTooLarge)
+	_, err = WilliamsPPlus1(context.Background(), n, &PPlus1Options{B1: 10, B2: MaxB2 + 1})
+	assert.ErrorIs(t, err, ErrBoundTooLarge)
+	assert.Equal(t, uint64(MaxB2), (&PPlus1Options{B1: 1 << 63}).b2())
+}
+
+func TestLucasV(t *testing.T) {
+	n := big.NewInt(1000003)
+	v := big.NewInt(5)
+	// V_{k+1} = v V_k - V_{k-1}
+	prev, cur := big.NewInt(2), new(big.Int).Set(v)
+	for k := uint64(1); k < 100; k++ {
+		assert.Equal(t, cur, lucasV(v, k, n), "k = %d", k)
+		next := new(big.Int).Mul(v, cur)
+		next.Sub(next, prev)
+		next.Mod(next, n)
+		prev, cur = cur, next
+	}
+}
@@ -0,0 +1,191 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+)
+
+// checkpointInterval is the minimum time between two saves of the checkpoint while proving.
+const checkpointInterval = 10 * time.Second
+
+// Stage is a stage of the proof of a number.
+type Stage string
+
+const (
+	// StageFactoring is factoring N-1.
+	StageFactoring Stage = "factoring"
+	// StageProving is searching for a base and computing the inverses, once N-1 is factored.
+	StageProving Stage = "proving"
+	// StageProved is the completion of the proof of N.
+	StageProved Stage = "proved"
+)
+
+// Progress describes the progress of ProveRegistry.
+type Progress struct {
+	// N is the number being proven.
+	N     *big.Int
+	Stage Stage
+	// Factor is the progress of factoring N-1 in StageFactoring, and nil in the other stages.
+	Factor *FactorProgress
+	// Resumed reports whether the proof of N was read from the checkpoint instead of being proven, in StageProved.
+	Resumed bool
+	// Done is the number of proofs completed so far.
+	Done int
+	// Pending is the number of numbers known to need a proof that is not completed yet, including N.
+	Pending int
+}
+
+// tracker counts the numbers proven by ProveRegistryContext,
+// reports their progress to ProveOptions.Progress and records it in ProveOptions.Checkpoint.
+// A nil *tracker does nothing.
+type tracker struct {
+	progress   func(Progress)
+	checkpoint *Checkpoint
+
+	mu      sync.Mutex
+	done    int
+	pending int
+	// lastSave is when the checkpoint was last saved
+	lastSave time.Time
+}
+
+func newTracker(opts *ProveOptions) *tracker {
+	if opts.Progress == nil && opts.Checkpoint == nil {
+		return nil
+	}
+	return &tracker{
+		progress:   opts.Progress,
+		checkpoint: opts.Checkpoint,
+	}
+}
+
+// schedule counts a number that needs a proof.
+func (t *tracker) schedule() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending++
+}
+
+// report reports that n entered stage. t.mu must be held.
+func (t *tracker) report(n *big.Int, stage Stage, factor *FactorProgress, resumed bool) {
+	if t.progress == nil {
+		return
+	}
+	t.progress(Progress{
+		N:       n,
+		Stage:   stage,
+		Factor:  factor,
+		Resumed: resumed,
+		Done:    t.done,
+		Pending: t.pending,
+	})
+}
+
+// proving reports that the proof of n entered StageProving.
+func (t *tracker) proving(n *big.Int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.report(n, StageProving, nil, false)
+}
+
+// saveDue reports whether the checkpoint is due to be saved, i.e., checkpointInterval has passed since it was last saved,
+// and if so, counts it as saved now. t.mu must be held.
+func (t *tracker) saveDue() bool {
+	if t.checkpoint == nil || time.Since(t.lastSave) < checkpointInterval {
+		return false
+	}
+	t.lastSave = time.Now()
+	return true
+}
+
+// proved counts the completed proof, records it in the checkpoint and reports it.
+// The checkpoint is saved if it is due, outside of t.mu.
+func (t *tracker) proved(proof *Proof, resumed bool) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	t.done++
+	t.pending--
+	save := false
+	if t.checkpoint != nil && !resumed {
+		t.checkpoint.addProof(proof)
+		save = t.saveDue()
+	}
+	t.report((*big.Int)(proof.N), StageProved, nil, resumed)
+	t.mu.Unlock()
+	if save {
+		return t.checkpoint.Save()
+	}
+	return nil
+}
+
+// flush saves the checkpoint, if any, with everything recorded since it was last saved.
+func (t *tracker) flush() error {
+	if t == nil || t.checkpoint == nil {
+		return nil
+	}
+	return t.checkpoint.Save()
+}
+
+// resume returns the proof of n in the checkpoint, or nil if there is none or it does not verify.
+func (t *tracker) resume(ctx context.Context, n *big.Int) *Proof {
+	if t == nil || t.checkpoint == nil {
+		return nil
+	}
+	proof, ok := t.checkpoint.proof(n)
+	if !ok || proof.CheckContext(ctx) != nil {
+		return nil
+	}
+	return proof
+}
+
+// factorOptions returns opts for factoring n-1, with the prime factors in the checkpoint as hints,
+// and reporting the progress of factoring.
+func (t *tracker) factorOptions(n *big.Int, opts *FactorOptions) *FactorOptions {
+	if t == nil {
+		return opts
+	}
+	factorOpts := FactorOptions{}
+	if opts != nil {
+		factorOpts = *opts
+	}
+	if t.checkpoint != nil {
+		factorOpts.Hints = append(slices.Clip(factorOpts.Hints), t.checkpoint.primes(n)...)
+	}
+	bound := new(big.Int).SetUint64(factorOpts.trialDivisionBound())
+	saved := 0
+	factorOpts.Progress = func(progress FactorProgress) {
+		if opts != nil && opts.Progress != nil {
+			opts.Progress(progress)
+		}
+		t.mu.Lock()
+		// prime factors found by trial division are found again quickly,
+		// and a finished factorization is not worth saving, since the proof follows soon
+		primes := slices.DeleteFunc(slices.Clone(progress.Primes), func(p *big.Int) bool {
+			return p.Cmp(bound) < 0
+		})
+		save := false
+		if t.checkpoint != nil && len(primes) > saved && progress.Remaining.Cmp(big.NewInt(1)) > 0 {
+			saved = len(primes)
+			t.checkpoint.setPrimes(n, primes)
+			save = t.saveDue()
+		}
+		t.report(n, StageFactoring, &progress, false)
+		t.mu.Unlock()
+		if save {
+			// the prime factors are only hints, so failing to save them is not fatal
+			_ = t.checkpoint.Save()
+		}
+	}
+	return &factorOpts
+}
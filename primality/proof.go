@@ -1,6 +1,8 @@
 package primality
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -70,29 +72,114 @@ func (i *Inverse) Check() error {
 }
 
 type Proof struct {
-	N                      *BigInt                      `json:"n"`
-	GeneralizedPocklington *GeneralizedPocklingtonProof `json:"generalized-pocklington,omitempty"`
+	N *BigInt
+	// Method is the proof that N is prime. It is nil if N = 2.
+	Method   ProofMethod
+	Metadata *Metadata
+}
+
+func (p Proof) MarshalJSON() ([]byte, error) {
+	n, err := json.Marshal(p.N)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(`{"n":`)
+	buf.Write(n)
+	if p.Method != nil {
+		method, err := json.Marshal(p.Method)
+		if err != nil {
+			return nil, err
+		}
+		key, err := json.Marshal(p.Method.Key())
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(method)
+	}
+	if p.Metadata != nil {
+		metadata, err := json.Marshal(p.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(`,"metadata":`)
+		buf.Write(metadata)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*p = Proof{}
+	for key, value := range fields {
+		if key == "n" {
+			p.N = new(BigInt)
+			if err := json.Unmarshal(value, p.N); err != nil {
+				return err
+			}
+			continue
+		}
+		if key == "metadata" {
+			p.Metadata = new(Metadata)
+			if err := json.Unmarshal(value, p.Metadata); err != nil {
+				return err
+			}
+			continue
+		}
+		newMethod := lookupProofMethod(key)
+		if newMethod == nil {
+			return fmt.Errorf("unknown proof method: %q", key)
+		}
+		var content map[string]json.RawMessage
+		if err := json.Unmarshal(value, &content); err != nil {
+			return err
+		}
+		if len(content) == 0 {
+			return fmt.Errorf("%w: %q is empty", ErrNoProof, key)
+		}
+		if p.Method != nil {
+			return fmt.Errorf("more than one proof method: %q and %q", p.Method.Key(), key)
+		}
+		method := newMethod()
+		if err := json.Unmarshal(value, method); err != nil {
+			return err
+		}
+		p.Method = method
+	}
+	return nil
+}
+
+// marshalContent returns the JSON encoding of the proof without its metadata.
+func (p *Proof) marshalContent() ([]byte, error) {
+	return json.Marshal(Proof{N: p.N, Method: p.Method})
 }
 
 // Check checks the correctness of the proof per se,
 // i.e., it does not check if its dependencies are correct.
 func (p *Proof) Check() error {
+	return p.CheckContext(context.Background())
+}
+
+// CheckContext is like Check, but gives up and returns ctx.Err() when ctx is done.
+func (p *Proof) CheckContext(ctx context.Context) error {
 	// if N = 2, N is prime.
 	N := (*big.Int)(p.N)
 	if N.Cmp(big.NewInt(2)) == 0 {
 		return nil
 	}
-	proved := false
-	if p.GeneralizedPocklington != nil {
-		if err := p.GeneralizedPocklington.Check(N); err != nil {
-			return err
-		}
-		proved = true
+	if p.Method == nil {
+		return fmt.Errorf("%w for %s", ErrNoProof, N.String())
 	}
-	if !proved {
-		return fmt.Errorf("no proof provided")
+	if method, ok := p.Method.(contextChecker); ok {
+		return method.CheckContext(ctx, N)
 	}
-	return nil
+	return p.Method.Check(N)
 }
 
 // Dep returns the dependencies of the proof.
@@ -102,8 +189,17 @@ func (p *Proof) Dep() []*big.Int {
 		return nil
 	}
 	deps := []*big.Int{}
-	if p.GeneralizedPocklington != nil {
-		deps = append(deps, p.GeneralizedPocklington.Dep()...)
+	if p.Method != nil {
+		deps = append(deps, p.Method.Dep()...)
 	}
 	return deps
 }
+
+// DepsSmallerThanN reports whether the proof method requires every dependency to be strictly smaller than N.
+// Registry.Check enforces this requirement.
+func (p *Proof) DepsSmallerThanN() bool {
+	if p.Method != nil && !p.Method.DepsSmallerThanN() {
+		return false
+	}
+	return true
+}
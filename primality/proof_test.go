@@ -2,6 +2,7 @@ package primality
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -40,7 +41,7 @@ func TestProofCheck181(t *testing.T) {
 	// https://safecurves.cr.yp.to/proof/181.html
 	cert := Proof{
 		N: (*BigInt)(big.NewInt(181)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(45)),
 				Factorization: []FactorEntry{
@@ -76,7 +77,7 @@ func TestProofCheck181(t *testing.T) {
 func TestProofCheck15(t *testing.T) {
 	cert := Proof{
 		N: (*BigInt)(big.NewInt(15)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(2)),
 				Factorization: []FactorEntry{
@@ -99,7 +100,7 @@ func TestProofCheck15(t *testing.T) {
 func TestProofCheck255(t *testing.T) {
 	cert := Proof{
 		N: (*BigInt)(big.NewInt(255)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(8)),
 				Factorization: []FactorEntry{
@@ -123,7 +124,7 @@ func TestProofCheck257(t *testing.T) {
 	// https://safecurves.cr.yp.to/proof/257.html
 	cert := Proof{
 		N: (*BigInt)(big.NewInt(257)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(256)),
 				Factorization: []FactorEntry{
@@ -152,7 +153,7 @@ func TestProofCheck257(t *testing.T) {
 func TestProofCheckInvalidMod(t *testing.T) {
 	cert := Proof{
 		N: (*BigInt)(big.NewInt(257)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(256)),
 				Factorization: []FactorEntry{
@@ -175,7 +176,7 @@ func TestProofCheckInvalidMod(t *testing.T) {
 func TestProofCheckInverseSetNotCorrect(t *testing.T) {
 	cert := Proof{
 		N: (*BigInt)(big.NewInt(257)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(256)),
 				Factorization: []FactorEntry{
@@ -192,5 +193,86 @@ func TestProofCheckInverseSetNotCorrect(t *testing.T) {
 			},
 		},
 	}
-	assert.EqualError(t, cert.Check(), "set of inverses is not correct")
+	err := cert.Check()
+	assert.EqualError(t, err, "set of inverses is not correct")
+	var pocklingtonErr *PocklingtonError
+	if assert.True(t, errors.As(err, &pocklingtonErr)) {
+		assert.Equal(t, big.NewInt(257), pocklingtonErr.N)
+		assert.Equal(t, GeneralizedPocklingtonKey, pocklingtonErr.Method)
+		assert.Equal(t, big.NewInt(2), pocklingtonErr.Factor)
+	}
+}
+
+func TestProofCheckInvalidInverseFactor(t *testing.T) {
+	// 3^128 - 1 = 255 modulo 257, whose inverse is 128, not 5
+	cert := Proof{
+		N: (*BigInt)(big.NewInt(257)),
+		Method: &GeneralizedPocklingtonProof{
+			A: &FactoredInt{
+				Int: (*BigInt)(big.NewInt(256)),
+				Factorization: []FactorEntry{
+					{Prime: (*BigInt)(big.NewInt(2)), Exponent: 8},
+				},
+			},
+			Base: (*BigInt)(big.NewInt(3)),
+			Inverses: []Inverse{
+				{
+					Mod:   (*BigInt)(big.NewInt(257)),
+					Value: (*BigInt)(big.NewInt(255)),
+					Inv:   (*BigInt)(big.NewInt(5)),
+				},
+			},
+		},
+	}
+	err := cert.Check()
+	var pocklingtonErr *PocklingtonError
+	if assert.True(t, errors.As(err, &pocklingtonErr)) {
+		assert.Equal(t, "invalid inverse", pocklingtonErr.Reason)
+		assert.Equal(t, big.NewInt(2), pocklingtonErr.Factor)
+	}
+}
+
+func TestProofCheckInvalidA(t *testing.T) {
+	cert := Proof{
+		N: (*BigInt)(big.NewInt(257)),
+		Method: &GeneralizedPocklingtonProof{
+			A: &FactoredInt{
+				Int: (*BigInt)(big.NewInt(256)),
+				Factorization: []FactorEntry{
+					{Prime: (*BigInt)(big.NewInt(2)), Exponent: 7},
+				},
+			},
+			Base:     (*BigInt)(big.NewInt(3)),
+			Inverses: []Inverse{},
+		},
+	}
+	err := cert.Check()
+	var pocklingtonErr *PocklingtonError
+	if assert.True(t, errors.As(err, &pocklingtonErr)) {
+		assert.Equal(t, "invalid A", pocklingtonErr.Reason)
+		assert.Nil(t, pocklingtonErr.Factor)
+	}
+}
+
+func TestProofUnmarshalEmptyMethod(t *testing.T) {
+	for _, data := range []string{
+		`{"n":"7","generalized-pocklington":null}`,
+		`{"n":"7","generalized-pocklington":{}}`,
+	} {
+		var proof Proof
+		assert.ErrorIs(t, json.Unmarshal([]byte(data), &proof), ErrNoProof, data)
+	}
+}
+
+func TestProofCheckMissingField(t *testing.T) {
+	var proof Proof
+	if !assert.NoError(t, json.Unmarshal([]byte(`{"n":"7","generalized-pocklington":{"base":"3"}}`), &proof)) {
+		return
+	}
+	err := proof.Check()
+	var pocklingtonErr *PocklingtonError
+	if assert.True(t, errors.As(err, &pocklingtonErr)) {
+		assert.Equal(t, "missing field", pocklingtonErr.Reason)
+	}
+	assert.Empty(t, proof.Dep())
 }
@@ -1,6 +1,7 @@
 package primality
 
 import (
+	"context"
 	"errors"
 	"math/big"
 )
@@ -11,6 +12,10 @@ var ErrNotProth = errors.New("not Proth number")
 //
 // https://en.wikipedia.org/wiki/Proth%27s_theorem
 func ProveProth(n *big.Int) (*Proof, error) {
+	return proveProth(context.Background(), n)
+}
+
+func proveProth(ctx context.Context, n *big.Int) (*Proof, error) {
 	if n.Cmp(big.NewInt(1)) <= 0 {
 		return nil, ErrNotPrime
 	}
@@ -48,17 +53,20 @@ func ProveProth(n *big.Int) (*Proof, error) {
 	}
 	base := big.NewInt(2)
 	for base.Cmp(big.NewInt(100)) < 0 {
-		inverses, err := checkGen(n,
+		inverses, err := checkGen(ctx, n,
 			aFactorization,
 			base,
 		)
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			base.Add(base, big.NewInt(1))
 			continue
 		}
 		return &Proof{
 			N: (*BigInt)(n),
-			GeneralizedPocklington: &GeneralizedPocklingtonProof{
+			Method: &GeneralizedPocklingtonProof{
 				A:        aFactorization,
 				Base:     (*BigInt)(base),
 				Inverses: inverses,
@@ -1,49 +1,79 @@
 package primality
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math/big"
 )
 
 var ErrNotPrime = errors.New("not prime")
 
-func findA(n *big.Int) *FactoredInt {
-	p := big.NewInt(2)
-	rem := big.NewInt(0).Set(n)
-	factors := []FactorEntry{}
-	for rem.Cmp(big.NewInt(1)) > 0 && !rem.ProbablyPrime(20) {
-		e := 0
-		for big.NewInt(0).Rem(rem, p).Cmp(big.NewInt(0)) == 0 {
-			rem.Div(rem, p)
-			e++
+// pollInterval is the number of iterations of a hot loop between two checks of ctx.
+const pollInterval = 1024
+
+// findA finds a factored divisor A of n = N-1 with A > n/A and gcd(A, n/A) = 1, as chosen by chooseA.
+// It stops factoring n as soon as the prime powers found make such an A.
+func findA(ctx context.Context, n *big.Int, opts *FactorOptions) (*FactoredInt, error) {
+	factors, unfactored, err := factorPartial(ctx, n, opts, func(factored *big.Int) bool {
+		return isValidA(n, factored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chooseA(n, factors, unfactored)
+}
+
+// isValidA reports whether a, a divisor of n = N-1, satisfies a > n/a and gcd(a, n/a) = 1.
+func isValidA(n, a *big.Int) bool {
+	b := new(big.Int).Div(n, a)
+	return a.Cmp(b) > 0 && new(big.Int).GCD(nil, nil, a, b).Cmp(big.NewInt(1)) == 0
+}
+
+// chooseA chooses A from the prime factors of n = N-1 and the composite factors of n that could not be split.
+// If the largest prime factor q of n appears once, A is q if q^2 > n, and n/q otherwise.
+// If n is not fully factored, A is the fully factored part of n.
+func chooseA(n *big.Int, factors []FactorEntry, unfactored []*big.Int) (*FactoredInt, error) {
+	if len(unfactored) > 0 {
+		a := big.NewInt(1)
+		for _, entry := range factors {
+			a.Mul(a, new(big.Int).Exp((*big.Int)(entry.Prime), big.NewInt(int64(entry.Exponent)), nil))
 		}
-		if e > 0 {
-			factors = append(factors, FactorEntry{Prime: (*BigInt)(new(big.Int).Set(p)), Exponent: e})
+		if !isValidA(n, a) {
+			return nil, fmt.Errorf("%w: %s", ErrFactorizationFailed, unfactored[0].String())
 		}
-		p.Add(p, big.NewInt(1))
-	}
-	if rem.Cmp(big.NewInt(1)) > 0 && n.Cmp(new(big.Int).Mul(rem, rem)) < 0 {
 		return &FactoredInt{
-			Int: (*BigInt)(rem),
-			Factorization: []FactorEntry{
-				{
-					Prime:    (*BigInt)(rem),
-					Exponent: 1,
-				},
-			},
+			Int:           (*BigInt)(a),
+			Factorization: factors,
+		}, nil
+	}
+	if len(factors) > 0 && factors[len(factors)-1].Exponent == 1 {
+		q := (*big.Int)(factors[len(factors)-1].Prime)
+		if n.Cmp(new(big.Int).Mul(q, q)) < 0 {
+			return &FactoredInt{
+				Int:           (*BigInt)(q),
+				Factorization: []FactorEntry{{Prime: (*BigInt)(q), Exponent: 1}},
+			}, nil
 		}
+		return &FactoredInt{
+			Int:           (*BigInt)(new(big.Int).Div(n, q)),
+			Factorization: factors[:len(factors)-1],
+		}, nil
 	}
 	return &FactoredInt{
-		Int:           (*BigInt)(new(big.Int).Div(n, rem)),
+		Int:           (*BigInt)(new(big.Int).Set(n)),
 		Factorization: factors,
-	}
+	}, nil
 }
 
-func checkGen(n *big.Int, a *FactoredInt, base *big.Int) ([]Inverse, error) {
+func checkGen(ctx context.Context, n *big.Int, a *FactoredInt, base *big.Int) ([]Inverse, error) {
 	nMinus1 := big.NewInt(0).Sub(n, big.NewInt(1))
 	invs := []Inverse{}
 	seen := map[string]struct{}{}
 	for _, entry := range a.Factorization {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		pr := (*big.Int)(entry.Prime)
 		exp := big.NewInt(0).Div(nMinus1, pr)
 		value := big.NewInt(0).Exp(base, exp, n)
@@ -55,6 +85,7 @@ func checkGen(n *big.Int, a *FactoredInt, base *big.Int) ([]Inverse, error) {
 		}
 		valueString := value.String()
 		if _, ok := seen[valueString]; !ok {
+			seen[valueString] = struct{}{}
 			invs = append(invs, Inverse{
 				Mod:   (*BigInt)(n),
 				Value: (*BigInt)(value),
@@ -66,7 +97,20 @@ func checkGen(n *big.Int, a *FactoredInt, base *big.Int) ([]Inverse, error) {
 	return invs, nil
 }
 
+// Prove finds a proof that n is prime.
+// It returns ErrNotPrime if n is not prime.
 func Prove(n *big.Int) (*Proof, error) {
+	return ProveContext(context.Background(), n)
+}
+
+// ProveContext is like Prove, but gives up and returns ctx.Err() when ctx is done.
+func ProveContext(ctx context.Context, n *big.Int) (*Proof, error) {
+	return prove(ctx, n, nil, nil, nil)
+}
+
+// prove is like ProveContext, but configured by opts and reporting progress to t.
+// The numbers in known are proven elsewhere, so depending on them costs nothing.
+func prove(ctx context.Context, n *big.Int, known map[string]struct{}, opts *ProveOptions, t *tracker) (*Proof, error) {
 	if n.Cmp(big.NewInt(2)) == 0 {
 		return &Proof{
 			N: (*BigInt)(n),
@@ -75,7 +119,10 @@ func Prove(n *big.Int) (*Proof, error) {
 	if !n.ProbablyPrime(20) {
 		return nil, ErrNotPrime
 	}
-	prothProof, err := ProveProth(n)
+	if opts.objective() != ObjectiveDefault {
+		return proveBest(ctx, n, known, opts, t)
+	}
+	prothProof, err := proveProth(ctx, n)
 	if err == nil {
 		return prothProof, nil
 	}
@@ -83,19 +130,32 @@ func Prove(n *big.Int) (*Proof, error) {
 		return nil, err
 	}
 	nMinus1 := big.NewInt(0).Sub(n, big.NewInt(1))
-	a := findA(nMinus1)
+	a, err := findA(ctx, nMinus1, t.factorOptions(n, opts.factor()))
+	if err != nil {
+		return nil, err
+	}
+	t.proving(n)
+	return provePocklington(ctx, n, a)
+}
+
+// provePocklington returns the generalized Pocklington proof of n with the given A and the smallest base that works.
+func provePocklington(ctx context.Context, n *big.Int, a *FactoredInt) (*Proof, error) {
 	base := big.NewInt(2)
 	for {
-		if invs, err := checkGen(n, a, base); err == nil {
+		invs, err := checkGen(ctx, n, a, base)
+		if err == nil {
 			return &Proof{
 				N: (*BigInt)(n),
-				GeneralizedPocklington: &GeneralizedPocklingtonProof{
+				Method: &GeneralizedPocklingtonProof{
 					A:        a,
 					Base:     (*BigInt)(base),
 					Inverses: invs,
 				},
 			}, nil
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		base.Add(base, big.NewInt(1))
 	}
 }
@@ -0,0 +1,242 @@
+package primality
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/big"
+	"sync"
+)
+
+// ProveOptions configures ProveRegistry. A nil *ProveOptions uses the defaults.
+type ProveOptions struct {
+	// Workers is the number of numbers proven concurrently. Values less than 2 mean proving sequentially.
+	// The resulting registry does not depend on Workers.
+	Workers int
+	// Factor configures the factoring of N-1.
+	Factor *FactorOptions
+	// Objective is what the prover minimizes when choosing among the proofs of a number.
+	// The default, ObjectiveDefault, spends the least time choosing.
+	Objective Objective
+	// Known are registries of numbers already proven. They are verified as if merged with Merge,
+	// and numbers they prove are not proven again. The proofs reused are copied into the result.
+	// Their axioms, and the proofs relying on them, are ignored unless TrustKnownAxioms is true.
+	Known []*Registry
+	// TrustKnownAxioms makes numbers that Known takes as axioms not proven again.
+	// The axioms relied on are copied into the result, where Registry.UsedAxioms reports them.
+	TrustKnownAxioms bool
+	// Progress, if not nil, is called whenever the proof of a number enters a stage,
+	// and whenever factoring N-1 makes progress. Calls are never concurrent, even with several workers.
+	Progress func(Progress)
+	// Checkpoint, if not nil, records every proof completed and the large prime factors of N-1 found,
+	// but not the state of the factoring methods. It is saved at most every 10 seconds while proving,
+	// and once more when proving ends, even by an error or cancellation. The proofs it contains are reused
+	// if they verify, so that a run interrupted can be resumed by proving again with the same checkpoint.
+	Checkpoint *Checkpoint
+}
+
+func (o *ProveOptions) factor() *FactorOptions {
+	if o == nil {
+		return nil
+	}
+	return o.Factor
+}
+
+func (o *ProveOptions) objective() Objective {
+	if o == nil {
+		return ObjectiveDefault
+	}
+	return o.Objective
+}
+
+// ProveRegistry proves that n is prime, together with every number the proof depends on, recursively.
+// The returned registry is verified and in canonical form.
+// It returns an error wrapping ErrInvalidKnownRegistry if opts.Known does not verify.
+func ProveRegistry(n *big.Int, opts *ProveOptions) (*Registry, error) {
+	return ProveRegistryContext(context.Background(), n, opts)
+}
+
+// ProveRegistryContext is like ProveRegistry, but gives up and returns ctx.Err() when ctx is done.
+func ProveRegistryContext(ctx context.Context, n *big.Int, opts *ProveOptions) (*Registry, error) {
+	if opts == nil {
+		opts = &ProveOptions{}
+	}
+	if !opts.Objective.valid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownObjective, opts.Objective)
+	}
+	known := &Registry{}
+	if len(opts.Known) > 0 {
+		var err error
+		if known, err = Merge(opts.Known...); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidKnownRegistry, err)
+		}
+		if !opts.TrustKnownAxioms {
+			if known, err = known.withoutAxioms(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	knownSet := map[string]struct{}{}
+	for _, proof := range known.Proofs {
+		knownSet[(*big.Int)(proof.N).String()] = struct{}{}
+	}
+	for _, axiom := range known.Axioms {
+		knownSet[(*big.Int)(axiom.N).String()] = struct{}{}
+	}
+	t := newTracker(opts)
+	var proofs []Proof
+	var err error
+	if opts.Workers < 2 {
+		proofs, err = proveSequential(ctx, n, knownSet, opts, t)
+	} else {
+		proofs, err = proveParallel(ctx, n, opts.Workers, knownSet, opts, t)
+	}
+	if flushErr := t.flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("failed to save checkpoint: %w", flushErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// copy the proofs and axioms of known numbers the new proofs depend on
+	reused := []*big.Int{}
+	for _, m := range append([]*big.Int{n}, depsOf(proofs)...) {
+		if _, ok := knownSet[m.String()]; ok {
+			reused = append(reused, m)
+		}
+	}
+	closure, err := known.Closure(reused)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := (&Registry{
+		Proofs: append(proofs, closure.Proofs...),
+		Axioms: closure.Axioms,
+		Metadata: &Metadata{
+			Generator: GeneratorVersion(),
+		},
+	}).canonicalCopy()
+	if err != nil {
+		return nil, err
+	}
+	if err := registry.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// proveOne proves n, or takes its proof from the checkpoint, wrapping errors other than cancellation with n.
+func proveOne(ctx context.Context, n *big.Int, known map[string]struct{}, opts *ProveOptions, t *tracker) (*Proof, error) {
+	if proof := t.resume(ctx, n); proof != nil {
+		return proof, t.proved(proof, true)
+	}
+	proof, err := prove(ctx, n, known, opts, t)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to prove %s: %w", n.String(), err)
+	}
+	if err := t.proved(proof, false); err != nil {
+		return nil, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return proof, nil
+}
+
+// depsOf returns the dependencies of proofs.
+func depsOf(proofs []Proof) []*big.Int {
+	deps := []*big.Int{}
+	for _, proof := range proofs {
+		deps = append(deps, proof.Dep()...)
+	}
+	return deps
+}
+
+// proveSequential proves n and its dependencies recursively, except for the numbers in known.
+func proveSequential(ctx context.Context, n *big.Int, known map[string]struct{}, opts *ProveOptions, t *tracker) ([]Proof, error) {
+	proofs := []Proof{}
+	seen := maps.Clone(known)
+	stack := []*big.Int{}
+	// push schedules n unless it was already scheduled
+	push := func(n *big.Int) {
+		if _, ok := seen[n.String()]; ok {
+			return
+		}
+		seen[n.String()] = struct{}{}
+		stack = append(stack, n)
+		t.schedule()
+	}
+	push(n)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		proof, err := proveOne(ctx, n, known, opts, t)
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, *proof)
+		for _, d := range proof.Dep() {
+			push(d)
+		}
+	}
+	return proofs, nil
+}
+
+// proveParallel is like proveSequential, but runs up to workers proofs at a time.
+// Each number is proven only once, even if it is a dependency of several numbers being proven.
+func proveParallel(ctx context.Context, n *big.Int, workers int, known map[string]struct{}, opts *ProveOptions, t *tracker) ([]Proof, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		proofs   []Proof
+		firstErr error
+		seen     = maps.Clone(known)
+	)
+	semaphore := make(chan struct{}, workers)
+	var prove func(n *big.Int)
+	// schedule starts proving n unless it was already scheduled. mu must be held.
+	schedule := func(n *big.Int) {
+		if _, ok := seen[n.String()]; ok {
+			return
+		}
+		seen[n.String()] = struct{}{}
+		t.schedule()
+		wg.Add(1)
+		go prove(n)
+	}
+	prove = func(n *big.Int) {
+		defer wg.Done()
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		proof, err := proveOne(ctx, n, known, opts, t)
+		<-semaphore
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+			return
+		}
+		proofs = append(proofs, *proof)
+		for _, d := range proof.Dep() {
+			schedule(d)
+		}
+	}
+	mu.Lock()
+	schedule(n)
+	mu.Unlock()
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return proofs, nil
+}
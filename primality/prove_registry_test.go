@@ -0,0 +1,177 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProveRegistry(t *testing.T) {
+	n, ok := new(big.Int).SetString("221360928884514619393", 10)
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	registry, err := ProveRegistry(n, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, registry.Check())
+	assert.Empty(t, registry.Lint([]*big.Int{n}))
+	last := registry.Proofs[len(registry.Proofs)-1]
+	assert.Equal(t, n, (*big.Int)(last.N))
+}
+
+func TestProveRegistryNotPrime(t *testing.T) {
+	_, err := ProveRegistry(big.NewInt(91), nil)
+	assert.ErrorIs(t, err, ErrNotPrime)
+}
+
+func TestProveRegistryContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ProveRegistryContext(ctx, big.NewInt(181), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProveRegistryParallel(t *testing.T) {
+	for _, s := range []string{"3221225473", "221360928884514619393", "1000000007"} {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			t.Fatalf("failed to parse %s", s)
+		}
+		sequential, err := ProveRegistry(n, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		parallel, err := ProveRegistry(n, &ProveOptions{Workers: 4})
+		if !assert.NoError(t, err) {
+			return
+		}
+		sequentialJSON, err := sequential.MarshalCanonical()
+		assert.NoError(t, err)
+		parallelJSON, err := parallel.MarshalCanonical()
+		assert.NoError(t, err)
+		assert.Equal(t, string(sequentialJSON), string(parallelJSON))
+	}
+	_, err := ProveRegistry(big.NewInt(91), &ProveOptions{Workers: 4})
+	assert.ErrorIs(t, err, ErrNotPrime)
+}
+
+// N-1 = 2 k p q with p and q of about 32 digits, which the restricted factoring methods cannot split without a hint.
+func TestProveRegistryHints(t *testing.T) {
+	p := nextPrime(new(big.Int).Lsh(big.NewInt(1), 100))
+	q := nextPrime(new(big.Int).Lsh(big.NewInt(1), 110))
+	n := new(big.Int)
+	for k := int64(1); ; k++ {
+		n.Mul(p, q)
+		n.Mul(n, big.NewInt(2*k))
+		n.Add(n, big.NewInt(1))
+		if n.ProbablyPrime(20) {
+			break
+		}
+	}
+	factorOpts := &FactorOptions{
+		PMinus1: &PMinus1Options{B1: 100, B2: 100},
+		PPlus1:  &PPlus1Options{B1: 100, B2: 100, Starts: 1},
+		SIQS:    &SIQSOptions{MaxDigits: 40},
+		ECM:     &ECMOptions{B1: 100, B2: 100, Curves: 1},
+	}
+	_, err := ProveRegistry(n, &ProveOptions{Factor: factorOpts})
+	assert.ErrorIs(t, err, ErrFactorizationFailed)
+
+	factorOpts.Hints = []*big.Int{p}
+	registry, err := ProveRegistry(n, &ProveOptions{Factor: factorOpts})
+	assert.NoError(t, err)
+	if assert.NotNil(t, registry) {
+		assert.NoError(t, registry.Check())
+		assert.Contains(t, registry.firstProofs(), q.String())
+	}
+}
+
+func TestProveRegistryKnown(t *testing.T) {
+	n, _ := new(big.Int).SetString("221360928884514619393", 10)
+	full, err := ProveRegistry(n, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// every number is known, so nothing is proven again
+	registry, err := ProveRegistry(n, &ProveOptions{Known: []*Registry{full}})
+	assert.NoError(t, err)
+	assert.Equal(t, full.Proofs, registry.Proofs)
+
+	// the largest dependency of n is taken as an axiom
+	proof := full.firstProofs()[n.String()]
+	var largest *big.Int
+	for _, d := range proof.Dep() {
+		if largest == nil || d.Cmp(largest) > 0 {
+			largest = d
+		}
+	}
+	axiom := Axiom{N: (*BigInt)(largest), Provenance: "test"}
+	registry, err = ProveRegistry(n, &ProveOptions{
+		Workers:          4,
+		Known:            []*Registry{{Axioms: []Axiom{axiom}}},
+		TrustKnownAxioms: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Axiom{axiom}, registry.Axioms)
+	assert.Equal(t, []Axiom{axiom}, registry.UsedAxioms())
+	assert.NotContains(t, registry.firstProofs(), largest.String())
+	assert.Contains(t, registry.firstProofs(), n.String())
+
+	// axioms are not trusted by default, even through the proofs relying on them
+	withAxiom := &Registry{Axioms: []Axiom{axiom}}
+	for _, proof := range full.Proofs {
+		if (*big.Int)(proof.N).Cmp(largest) != 0 {
+			withAxiom.Proofs = append(withAxiom.Proofs, proof)
+		}
+	}
+	registry, err = ProveRegistry(n, &ProveOptions{Known: []*Registry{withAxiom}})
+	assert.NoError(t, err)
+	assert.Empty(t, registry.Axioms)
+	assert.Contains(t, registry.firstProofs(), largest.String())
+	assert.Equal(t, full.firstProofs()[n.String()], registry.firstProofs()[n.String()])
+}
+
+func TestProveRegistryInvalidKnown(t *testing.T) {
+	invalid := &Registry{Proofs: []Proof{{N: (*BigInt)(big.NewInt(7))}}}
+	_, err := ProveRegistry(big.NewInt(181), &ProveOptions{Known: []*Registry{invalid}})
+	assert.ErrorIs(t, err, ErrInvalidKnownRegistry)
+}
+
+func TestProveRegistryProgress(t *testing.T) {
+	n, ok := new(big.Int).SetString("221360928884514619393", 10)
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	for _, workers := range []int{1, 4} {
+		events := []Progress{}
+		registry, err := ProveRegistry(n, &ProveOptions{
+			Workers: workers,
+			Progress: func(p Progress) {
+				events = append(events, p)
+			},
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		proved := map[string]struct{}{}
+		for _, event := range events {
+			if event.Stage == StageProved {
+				proved[event.N.String()] = struct{}{}
+				assert.False(t, event.Resumed)
+			}
+			if event.Stage == StageFactoring {
+				assert.NotNil(t, event.Factor)
+			}
+		}
+		assert.Len(t, proved, len(registry.Proofs))
+		last := events[len(events)-1]
+		assert.Equal(t, StageProved, last.Stage)
+		assert.Equal(t, len(registry.Proofs), last.Done)
+		assert.Equal(t, 0, last.Pending)
+	}
+}
@@ -1,6 +1,7 @@
 package primality
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
@@ -33,6 +34,17 @@ func TestProveLarge(t *testing.T) {
 	}
 }
 
+func TestProveContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bigInt, ok := big.NewInt(0).SetString("221360928884514619393", 10)
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	_, err := ProveContext(ctx, bigInt)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func BenchmarkProve100(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for i := int64(2); i < 100; i++ {
@@ -44,3 +56,24 @@ func BenchmarkProve100(b *testing.B) {
 		}
 	}
 }
+
+func TestCheckGenDuplicatePrime(t *testing.T) {
+	// the same prime listed twice yields the same value twice, which needs only one inverse
+	a := &FactoredInt{
+		Int: (*BigInt)(big.NewInt(4)),
+		Factorization: []FactorEntry{
+			{Prime: (*BigInt)(big.NewInt(2)), Exponent: 1},
+			{Prime: (*BigInt)(big.NewInt(2)), Exponent: 1},
+		},
+	}
+	invs, err := checkGen(context.Background(), big.NewInt(5), a, big.NewInt(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, invs, 1)
+	proof := Proof{
+		N:      (*BigInt)(big.NewInt(5)),
+		Method: &GeneralizedPocklingtonProof{A: a, Base: (*BigInt)(big.NewInt(2)), Inverses: invs},
+	}
+	assert.NoError(t, proof.Check())
+}
@@ -1,34 +1,249 @@
 package primality
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 )
 
 var ErrMissingDependency = errors.New("missing dependency")
 
 type Registry struct {
 	Proofs []Proof `json:"proofs"`
+	// Axioms are numbers assumed to be prime. Proofs may depend on them.
+	Axioms   []Axiom   `json:"axioms,omitempty"`
+	Metadata *Metadata `json:"metadata,omitempty"`
 }
 
-// Check checks if the proofs in the registry is correct and self-contained.
+// Check checks if the proofs in the registry is correct and self-contained,
+// taking the axioms for granted.
+// Failures are reported as *DependencyError.
 func (r *Registry) Check() error {
+	return r.CheckContext(context.Background())
+}
+
+// CheckContext is like Check, but gives up and returns ctx.Err() when ctx is done.
+func (r *Registry) CheckContext(ctx context.Context) error {
+	return r.CheckWithOptions(ctx, nil)
+}
+
+// CheckOptions configures Registry.CheckWithOptions.
+type CheckOptions struct {
+	// Cache, if not nil, is consulted before verifying each proof and records the proofs verified.
+	// Dependencies are checked regardless of the cache.
+	Cache *Cache
+}
+
+// CheckWithOptions is like CheckContext, but configured by opts. opts may be nil.
+func (r *Registry) CheckWithOptions(ctx context.Context, opts *CheckOptions) error {
+	if opts == nil {
+		opts = &CheckOptions{}
+	}
 	seen := map[string]struct{}{}
-	for _, proof := range r.Proofs {
-		// if the proof is incorrect, there is no way the registry is correct
-		if err := proof.Check(); err != nil {
+	for _, axiom := range r.Axioms {
+		seen[(*big.Int)(axiom.N).String()] = struct{}{}
+	}
+	for i, proof := range r.Proofs {
+		if err := r.checkProof(ctx, i, opts.Cache); err != nil {
 			return err
 		}
 		seen[(*big.Int)(proof.N).String()] = struct{}{}
 	}
-	for _, proof := range r.Proofs {
+	for i, proof := range r.Proofs {
 		dep := proof.Dep()
 		for _, d := range dep {
 			if _, ok := seen[d.String()]; !ok {
-				return errors.Join(fmt.Errorf("error in verifying %s (missing dependency: %s)", (*big.Int)(proof.N).String(), d.String()), ErrMissingDependency)
+				return &DependencyError{
+					Chain: r.chainTo((*big.Int)(proof.N)),
+					Proof: i,
+					Err:   fmt.Errorf("%w: %s", ErrMissingDependency, d.String()),
+				}
+			}
+			if proof.DepsSmallerThanN() && d.Cmp((*big.Int)(proof.N)) >= 0 {
+				return &DependencyError{
+					Chain: r.chainTo((*big.Int)(proof.N)),
+					Proof: i,
+					Err:   fmt.Errorf("%w: %s", ErrDependencyNotSmaller, d.String()),
+				}
+			}
+		}
+	}
+	if cycle := r.findCycle(); cycle != nil {
+		return &CycleError{Cycle: cycle}
+	}
+	return nil
+}
+
+// findCycle returns a cycle in the dependency graph of the registry, or nil if there is none.
+func (r *Registry) findCycle() []*big.Int {
+	deps := r.depMap()
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	path := []*big.Int{}
+	var dfs func(n *big.Int) []*big.Int
+	dfs = func(n *big.Int) []*big.Int {
+		key := n.String()
+		switch state[key] {
+		case visiting:
+			for i, m := range path {
+				if m.Cmp(n) == 0 {
+					return append([]*big.Int{}, path[i:]...)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[key] = visiting
+		path = append(path, n)
+		for _, d := range deps[key] {
+			if cycle := dfs(d); cycle != nil {
+				return cycle
 			}
 		}
+		path = path[:len(path)-1]
+		state[key] = visited
+		return nil
+	}
+	for _, proof := range r.Proofs {
+		if cycle := dfs((*big.Int)(proof.N)); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// checkProof checks the i-th proof per se, skipping it if cache says it was verified before.
+func (r *Registry) checkProof(ctx context.Context, i int, cache *Cache) error {
+	proof := &r.Proofs[i]
+	key := ""
+	if cache != nil {
+		// a proof without a key cannot be canonicalized, and will be rejected below
+		if k, err := cacheKey(proof); err == nil {
+			if cache.contains(k) {
+				return nil
+			}
+			key = k
+		}
+	}
+	// if the proof is incorrect, there is no way the registry is correct
+	if err := proof.CheckContext(ctx); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return &DependencyError{Chain: r.chainTo((*big.Int)(proof.N)), Proof: i, Err: err}
+	}
+	if cache != nil && key != "" {
+		cache.add(key)
 	}
 	return nil
 }
+
+// chainTo returns a shortest chain of dependencies from a number nothing depends on to n.
+func (r *Registry) chainTo(n *big.Int) []*big.Int {
+	dependents := map[string][]*big.Int{}
+	for _, proof := range r.Proofs {
+		for _, d := range proof.Dep() {
+			dependents[d.String()] = append(dependents[d.String()], (*big.Int)(proof.N))
+		}
+	}
+	// BFS towards dependents, remembering which number we came from
+	next := map[string]*big.Int{n.String(): nil}
+	queue := []*big.Int{n}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if len(dependents[cur.String()]) == 0 {
+			chain := []*big.Int{}
+			for c := cur; c != nil; c = next[c.String()] {
+				chain = append(chain, c)
+			}
+			return chain
+		}
+		for _, d := range dependents[cur.String()] {
+			if _, ok := next[d.String()]; ok {
+				continue
+			}
+			next[d.String()] = cur
+			queue = append(queue, d)
+		}
+	}
+	// every number depending on n is part of a cycle
+	return []*big.Int{n}
+}
+
+// depMap maps each number proven in the registry to its dependencies.
+func (r *Registry) depMap() map[string][]*big.Int {
+	deps := map[string][]*big.Int{}
+	for _, proof := range r.Proofs {
+		n := (*big.Int)(proof.N).String()
+		deps[n] = append(deps[n], proof.Dep()...)
+	}
+	return deps
+}
+
+// topologicalOrder sorts proofs so that every proof appears after the proofs of its dependencies.
+// Among proofs whose dependencies are all placed, the one with the smallest N comes first;
+// proofs of the same N keep their relative order.
+// Dependencies that are not proven in proofs are ignored.
+func topologicalOrder(proofs []Proof) ([]Proof, error) {
+	indices := map[string][]int{}
+	for i, proof := range proofs {
+		n := (*big.Int)(proof.N).String()
+		indices[n] = append(indices[n], i)
+	}
+	// remaining[i] is the number of dependencies of proofs[i] that are not placed yet
+	remaining := make([]int, len(proofs))
+	dependents := map[string][]int{}
+	for i, proof := range proofs {
+		for _, d := range proof.Dep() {
+			if _, ok := indices[d.String()]; !ok {
+				continue
+			}
+			remaining[i]++
+			dependents[d.String()] = append(dependents[d.String()], i)
+		}
+	}
+	ready := []int{}
+	for i := range proofs {
+		if remaining[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	// placed counts how many proofs of each number are placed;
+	// a number counts as placed once all of its proofs are.
+	placed := map[string]int{}
+	sorted := make([]Proof, 0, len(proofs))
+	for len(ready) > 0 {
+		slices.SortFunc(ready, func(i, j int) int {
+			if c := (*big.Int)(proofs[i].N).Cmp((*big.Int)(proofs[j].N)); c != 0 {
+				return c
+			}
+			return i - j
+		})
+		i := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, proofs[i])
+		n := (*big.Int)(proofs[i].N).String()
+		placed[n]++
+		if placed[n] < len(indices[n]) {
+			continue
+		}
+		for _, j := range dependents[n] {
+			remaining[j]--
+			if remaining[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	if len(sorted) < len(proofs) {
+		r := &Registry{Proofs: proofs}
+		return nil, &CycleError{Cycle: r.findCycle()}
+	}
+	return sorted, nil
+}
@@ -1,6 +1,8 @@
 package primality
 
 import (
+	"context"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -11,7 +13,7 @@ func TestRegistryCheckSuccess(t *testing.T) {
 	// https://safecurves.cr.yp.to/proof/3.html
 	cert3 := Proof{
 		N: (*BigInt)(big.NewInt(3)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(2)),
 				Factorization: []FactorEntry{
@@ -41,7 +43,7 @@ func TestRegistryCheckInsufficient(t *testing.T) {
 	// https://safecurves.cr.yp.to/proof/181.html
 	cert := Proof{
 		N: (*BigInt)(big.NewInt(181)),
-		GeneralizedPocklington: &GeneralizedPocklingtonProof{
+		Method: &GeneralizedPocklingtonProof{
 			A: &FactoredInt{
 				Int: (*BigInt)(big.NewInt(45)),
 				Factorization: []FactorEntry{
@@ -69,3 +71,63 @@ func TestRegistryCheckInsufficient(t *testing.T) {
 	}
 	assert.Contains(t, registry.Check().Error(), ErrMissingDependency.Error())
 }
+
+func TestRegistryCheckContextCanceled(t *testing.T) {
+	proof, err := Prove(big.NewInt(257))
+	if !assert.NoError(t, err) {
+		return
+	}
+	registry := &Registry{
+		Proofs: []Proof{{N: (*BigInt)(big.NewInt(2))}, *proof},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, registry.CheckContext(ctx), context.Canceled)
+}
+
+func TestRegistryCheckDependencyChain(t *testing.T) {
+	registry := &Registry{}
+	// 11 depends on 5, which depends on 2
+	for _, n := range []int64{11, 5} {
+		proof, err := Prove(big.NewInt(n))
+		if !assert.NoError(t, err) {
+			return
+		}
+		registry.Proofs = append(registry.Proofs, *proof)
+	}
+	err := registry.Check()
+	assert.ErrorIs(t, err, ErrMissingDependency)
+	var depErr *DependencyError
+	if assert.True(t, errors.As(err, &depErr)) {
+		assert.Equal(t, []*big.Int{big.NewInt(11), big.NewInt(5)}, depErr.Chain)
+		assert.Equal(t, big.NewInt(5), depErr.N())
+	}
+}
+
+func TestRegistryFindCycle(t *testing.T) {
+	// proofs are not valid; only their dependencies matter
+	dependsOn := func(n, d int64) Proof {
+		return Proof{
+			N: (*BigInt)(big.NewInt(n)),
+			Method: &GeneralizedPocklingtonProof{
+				A: &FactoredInt{
+					Int: (*BigInt)(big.NewInt(d)),
+					Factorization: []FactorEntry{
+						{Prime: (*BigInt)(big.NewInt(d)), Exponent: 1},
+					},
+				},
+			},
+		}
+	}
+	registry := &Registry{
+		Proofs: []Proof{dependsOn(7, 3), dependsOn(3, 5), dependsOn(5, 3)},
+	}
+	cycle := registry.findCycle()
+	assert.Equal(t, []*big.Int{big.NewInt(3), big.NewInt(5)}, cycle)
+	assert.EqualError(t, &CycleError{Cycle: cycle}, "cyclic dependency: 3 -> 5 -> 3")
+
+	registry = &Registry{
+		Proofs: []Proof{dependsOn(7, 3), dependsOn(3, 2), {N: (*BigInt)(big.NewInt(2))}},
+	}
+	assert.Nil(t, registry.findCycle())
+}
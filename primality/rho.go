@@ -0,0 +1,98 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+)
+
+// rhoBatch is the number of products accumulated before taking a gcd in PollardRho.
+const rhoBatch = 128
+
+// rhoTries is the number of polynomials x^2 + c PollardRho tries.
+const rhoTries = 8
+
+// PollardRho finds a nontrivial factor of the composite n with Brent's variant of Pollard's rho method.
+// It tries another polynomial when the cycle of one polynomial yields no factor,
+// evaluates each polynomial at most about iterations times, and returns ErrNoFactorFound if it gives up.
+// The result is deterministic.
+func PollardRho(ctx context.Context, n *big.Int, iterations int) (*big.Int, error) {
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	for c := int64(1); c <= rhoTries; c++ {
+		d, err := brent(ctx, n, big.NewInt(c), iterations)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			return d, nil
+		}
+	}
+	return nil, ErrNoFactorFound
+}
+
+// brent runs Brent's cycle detection on x -> x^2 + c mod n.
+// It returns nil if the cycle yields no nontrivial factor, and ErrNoFactorFound if it runs out of iterations,
+// in which case trying another polynomial is unlikely to help.
+func brent(ctx context.Context, n, c *big.Int, iterations int) (*big.Int, error) {
+	one := big.NewInt(1)
+	// count is the number of evaluations of f so far
+	count := 0
+	f := func(x *big.Int) {
+		x.Mul(x, x)
+		x.Add(x, c)
+		x.Mod(x, n)
+		count++
+	}
+	y := big.NewInt(2)
+	x := new(big.Int)
+	ys := new(big.Int)
+	q := big.NewInt(1)
+	g := big.NewInt(1)
+	diff := new(big.Int)
+	for r := 1; g.Cmp(one) == 0; r *= 2 {
+		x.Set(y)
+		for i := 0; i < r; i++ {
+			if i%rhoBatch == 0 {
+				if count >= iterations {
+					return nil, ErrNoFactorFound
+				}
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			f(y)
+		}
+		for k := 0; k < r && g.Cmp(one) == 0; k += rhoBatch {
+			if count >= iterations {
+				return nil, ErrNoFactorFound
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			ys.Set(y)
+			for i := 0; i < min(rhoBatch, r-k); i++ {
+				f(y)
+				diff.Sub(x, y)
+				q.Mul(q, diff.Abs(diff))
+				q.Mod(q, n)
+			}
+			g.GCD(nil, nil, q, n)
+		}
+	}
+	if g.Cmp(n) == 0 {
+		// the batch overshot; redo it one step at a time
+		for {
+			f(ys)
+			diff.Sub(x, ys)
+			g.GCD(nil, nil, diff.Abs(diff), n)
+			if g.Cmp(one) != 0 {
+				break
+			}
+		}
+	}
+	if g.Cmp(n) == 0 {
+		return nil, nil
+	}
+	return g, nil
+}
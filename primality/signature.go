@@ -0,0 +1,122 @@
+package primality
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const SignatureAlgorithmEd25519 = "ed25519"
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrUntrustedSigner  = errors.New("untrusted signer")
+)
+
+// Signature is a detached signature over the canonical encoding of a registry (see Registry.MarshalCanonical).
+// It covers every proof, axiom and metadata field except Metadata.FetchedAt.
+// Keys and signatures are hex-encoded.
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public-key"`
+	Signature string `json:"signature"`
+}
+
+// SignRegistry signs the canonical encoding of reg with key.
+// The signature binds every factorization and axiom as published. Since the canonical form of a valid
+// generalized Pocklington proof has the smallest base, the signature also verifies if only the base was changed.
+func SignRegistry(reg *Registry, key ed25519.PrivateKey) (*Signature, error) {
+	message, err := reg.signedMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{
+		Algorithm: SignatureAlgorithmEd25519,
+		PublicKey: hex.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature: hex.EncodeToString(ed25519.Sign(key, message)),
+	}, nil
+}
+
+// VerifyRegistrySignature checks that sig is a valid signature over the canonical encoding of reg made by trusted.
+// It returns an error wrapping ErrUntrustedSigner if sig was made with another key,
+// and one wrapping ErrInvalidSignature if sig does not match reg.
+// It does not check the proofs in reg; use Registry.Check for that.
+func VerifyRegistrySignature(reg *Registry, sig *Signature, trusted ed25519.PublicKey) error {
+	if sig.Algorithm != SignatureAlgorithmEd25519 {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidSignature, sig.Algorithm)
+	}
+	publicKey, err := ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !publicKey.Equal(trusted) {
+		return fmt.Errorf("%w: %s", ErrUntrustedSigner, sig.PublicKey)
+	}
+	signature, err := hex.DecodeString(sig.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	message, err := reg.signedMessage()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParsePublicKey parses a hex-encoded Ed25519 public key. Surrounding whitespace is ignored.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key length: %d", len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// ParsePrivateKey parses a hex-encoded Ed25519 private key seed. Surrounding whitespace is ignored.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid private key length: %d", len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ReadSignatureFile reads a signature from the JSON file name.
+func ReadSignatureFile(name string) (*Signature, error) {
+	dat, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	var sig Signature
+	if err := json.Unmarshal(dat, &sig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", name, err)
+	}
+	return &sig, nil
+}
+
+// signedMessage returns the canonical encoding of reg without Metadata.FetchedAt,
+// so that fetching the same source again does not invalidate a signature.
+func (r *Registry) signedMessage() ([]byte, error) {
+	canonical, err := r.canonicalCopy()
+	if err != nil {
+		return nil, err
+	}
+	canonical.Metadata = canonical.Metadata.withoutVolatile()
+	for i := range canonical.Proofs {
+		canonical.Proofs[i].Metadata = canonical.Proofs[i].Metadata.withoutVolatile()
+	}
+	return canonical.MarshalCanonical()
+}
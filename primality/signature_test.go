@@ -0,0 +1,90 @@
+package primality
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrySignature(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	key, err := ParsePrivateKey(hex.EncodeToString(seed))
+	if !assert.NoError(t, err) {
+		return
+	}
+	publicKey := key.Public().(ed25519.PublicKey)
+	proof, err := Prove(big.NewInt(257))
+	if !assert.NoError(t, err) {
+		return
+	}
+	reg := &Registry{
+		Proofs: []Proof{*proof, {N: (*BigInt)(big.NewInt(2))}},
+	}
+	sig, err := SignRegistry(reg, key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, hex.EncodeToString(publicKey), sig.PublicKey)
+	assert.NoError(t, VerifyRegistrySignature(reg, sig, publicKey))
+
+	// the signature covers the canonical form, so the order of proofs does not matter
+	reordered := &Registry{
+		Proofs: []Proof{reg.Proofs[1], reg.Proofs[0]},
+	}
+	assert.NoError(t, VerifyRegistrySignature(reordered, sig, publicKey))
+
+	otherKey, err := ParsePublicKey(hex.EncodeToString(make([]byte, ed25519.PublicKeySize)))
+	if assert.NoError(t, err) {
+		assert.ErrorIs(t, VerifyRegistrySignature(reg, sig, otherKey), ErrUntrustedSigner)
+	}
+
+	tampered := &Registry{
+		Proofs: reg.Proofs,
+		Axioms: []Axiom{{N: (*BigInt)(big.NewInt(7)), Provenance: "injected"}},
+	}
+	assert.ErrorIs(t, VerifyRegistrySignature(tampered, sig, publicKey), ErrInvalidSignature)
+}
+
+func TestRegistrySignatureIgnoresBase(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	publicKey := key.Public().(ed25519.PublicKey)
+	proof5 := func(base int64) *Registry {
+		return &Registry{
+			Proofs: []Proof{
+				{N: (*BigInt)(big.NewInt(2))},
+				{
+					N: (*BigInt)(big.NewInt(5)),
+					Method: &GeneralizedPocklingtonProof{
+						A: &FactoredInt{
+							Int:           (*BigInt)(big.NewInt(4)),
+							Factorization: []FactorEntry{{Prime: (*BigInt)(big.NewInt(2)), Exponent: 2}},
+						},
+						Base: (*BigInt)(big.NewInt(base)),
+						Inverses: []Inverse{
+							{Mod: (*BigInt)(big.NewInt(5)), Value: (*BigInt)(big.NewInt(3)), Inv: (*BigInt)(big.NewInt(2))},
+						},
+					},
+				},
+			},
+		}
+	}
+	reg := proof5(2)
+	sameProof := proof5(13)
+	// both proofs are valid, with the same inverses
+	if !assert.NoError(t, reg.Check()) || !assert.NoError(t, sameProof.Check()) {
+		return
+	}
+	sig, err := SignRegistry(reg, key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, VerifyRegistrySignature(reg, sig, publicKey))
+	assert.NoError(t, VerifyRegistrySignature(sameProof, sig, publicKey))
+
+	changed := proof5(13)
+	changed.Axioms = []Axiom{{N: (*BigInt)(big.NewInt(7)), Provenance: "test"}}
+	assert.ErrorIs(t, VerifyRegistrySignature(changed, sig, publicKey), ErrInvalidSignature)
+}
@@ -0,0 +1,514 @@
+package primality
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSIQSMaxDigits = 70
+	// siqsMinDigits is the number of decimal digits below which SIQS does not try to factor a number.
+	siqsMinDigits = 20
+	// siqsSmallPrime is the bound below which primes are not sieved with.
+	siqsSmallPrime = 50
+	// siqsLargePrimeMultiplier is the ratio of the large prime bound to the largest prime in the factor base.
+	siqsLargePrimeMultiplier = 64
+	// siqsExtraRelations is the number of relations collected in addition to the number of columns of the matrix.
+	siqsExtraRelations = 32
+	// siqsMaxRepeatedA is the number of times in a row a used polynomial coefficient A may be generated
+	// before SIQS gives up.
+	siqsMaxRepeatedA = 1000
+)
+
+// siqsParams lists the factor base size and the sieve radius by the number of decimal digits of n.
+var siqsParams = []struct {
+	digits, factorBaseSize, radius int
+}{
+	{20, 100, 1 << 15},
+	{30, 200, 1 << 15},
+	{40, 500, 1 << 15},
+	{50, 1200, 1 << 16},
+	{60, 2500, 1 << 16},
+	{70, 5000, 1 << 17},
+	{80, 9000, 1 << 17},
+	{90, 15000, 1 << 18},
+}
+
+// SIQSOptions configures SIQS. A nil *SIQSOptions uses the defaults.
+type SIQSOptions struct {
+	// MaxDigits is the maximum number of decimal digits of a composite factor the factoring pipeline uses SIQS on.
+	// 0 means 70, and a negative value disables SIQS in the pipeline. SIQS itself ignores MaxDigits.
+	MaxDigits int
+	// FactorBaseSize is the number of primes in the factor base. 0 chooses it from the size of n.
+	FactorBaseSize int
+	// SieveRadius is M such that the sieve interval is [-M, M). 0 chooses it from the size of n.
+	SieveRadius int
+}
+
+func (o *SIQSOptions) maxDigits() int {
+	if o == nil || o.MaxDigits == 0 {
+		return defaultSIQSMaxDigits
+	}
+	return o.MaxDigits
+}
+
+// params returns the factor base size and the sieve radius for a number with the given number of digits.
+func (o *SIQSOptions) params(digits int) (int, int) {
+	i := 0
+	for i+1 < len(siqsParams) && siqsParams[i+1].digits <= digits {
+		i++
+	}
+	lo := siqsParams[i]
+	factorBaseSize, radius := lo.factorBaseSize, lo.radius
+	if i+1 < len(siqsParams) && digits > lo.digits {
+		hi := siqsParams[i+1]
+		factorBaseSize += (hi.factorBaseSize - lo.factorBaseSize) * (digits - lo.digits) / (hi.digits - lo.digits)
+	}
+	if o != nil && o.FactorBaseSize > 0 {
+		factorBaseSize = o.FactorBaseSize
+	}
+	if o != nil && o.SieveRadius > 0 {
+		radius = o.SieveRadius
+	}
+	return factorBaseSize, radius
+}
+
+// SIQS finds a nontrivial factor of the composite n with the self-initialising quadratic sieve,
+// using the single large prime variation. n must not be a perfect power.
+// Numbers with fewer than 20 decimal digits are left to other methods, and SIQS returns ErrNoFactorFound for them.
+// The result is deterministic.
+func SIQS(ctx context.Context, n *big.Int, opts *SIQSOptions) (*big.Int, error) {
+	if n.Bit(0) == 0 {
+		return big.NewInt(2), nil
+	}
+	digits := len(n.String())
+	if digits < siqsMinDigits {
+		return nil, ErrNoFactorFound
+	}
+	factorBaseSize, radius := opts.params(digits)
+	s, factor := newSIQS(n, factorBaseSize, radius)
+	if factor != nil {
+		return factor, nil
+	}
+	for len(s.relations) < len(s.primes)+1+siqsExtraRelations {
+		if err := s.nextA(); err != nil {
+			return nil, err
+		}
+		for i := 0; i < 1<<(len(s.aIndices)-1); i++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				s.nextB(i)
+			}
+			s.sieve()
+		}
+	}
+	for _, dep := range s.dependencies() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if factor := s.factorFrom(dep); factor != nil {
+			return factor, nil
+		}
+	}
+	return nil, ErrNoFactorFound
+}
+
+// siqsRelation records y with y^2 = (-1)^e0 prod p_j^ej * large^2 modulo n,
+// where the exponents are given by cols: column 0 is -1, and column j+1 is the j-th prime of the factor base.
+type siqsRelation struct {
+	y     *big.Int
+	cols  []int
+	large *big.Int
+}
+
+type siqs struct {
+	n       *big.Int
+	radius  int
+	primes  []uint64
+	roots   []uint64
+	logs    []byte
+	lpBound uint64
+	// threshold is the sieve value above which a position is checked by trial division.
+	threshold byte
+	rng       *rand.Rand
+	// relations are full relations, and partials are relations with a large prime not combined yet,
+	// by the large prime.
+	relations []siqsRelation
+	partials  map[uint64]siqsRelation
+	usedA     map[string]struct{}
+	sieveBuf  []byte
+
+	// the current polynomial (A x + B)^2 - n
+	a        *big.Int
+	aIndices []int
+	isA      []bool
+	bTerms   []*big.Int
+	bSigns   []int
+	b        *big.Int
+	// ainv[j] = A^-1 mod p_j, and bainv2[l][j] = 2 B_l A^-1 mod p_j
+	ainv   []uint64
+	bainv2 [][]uint64
+	// soln1 and soln2 are the roots of ((A x + B)^2 - n) / A modulo p_j
+	soln1, soln2 []uint64
+}
+
+// newSIQS builds the factor base of n. If a prime in it divides n, it returns the prime instead.
+func newSIQS(n *big.Int, factorBaseSize, radius int) (*siqs, *big.Int) {
+	s := &siqs{
+		n:        n,
+		radius:   radius,
+		primes:   []uint64{2},
+		roots:    []uint64{1},
+		logs:     []byte{1},
+		rng:      rand.New(rand.NewSource(0)),
+		partials: map[uint64]siqsRelation{},
+		usedA:    map[string]struct{}{},
+		sieveBuf: make([]byte, 2*radius),
+	}
+	r := new(big.Int)
+	for p := uint64(3); len(s.primes) < factorBaseSize; p += 2 {
+		if !big.NewInt(int64(p)).ProbablyPrime(0) {
+			continue
+		}
+		bp := new(big.Int).SetUint64(p)
+		r.Mod(n, bp)
+		if r.Sign() == 0 {
+			return nil, bp
+		}
+		if big.Jacobi(r, bp) != 1 {
+			continue
+		}
+		s.primes = append(s.primes, p)
+		s.roots = append(s.roots, new(big.Int).ModSqrt(r, bp).Uint64())
+		s.logs = append(s.logs, byte(math.Round(math.Log2(float64(p)))))
+	}
+	pmax := s.primes[len(s.primes)-1]
+	s.lpBound = pmax * siqsLargePrimeMultiplier
+	// |g(x)| is at most about M sqrt(n / 2)
+	maxBits := math.Log2(float64(radius)) + float64(n.BitLen()-1)/2
+	threshold := maxBits - math.Log2(float64(s.lpBound)) - 4
+	s.threshold = byte(max(threshold, 1))
+	k := len(s.primes)
+	s.isA = make([]bool, k)
+	s.ainv = make([]uint64, k)
+	s.soln1 = make([]uint64, k)
+	s.soln2 = make([]uint64, k)
+	return s, nil
+}
+
+// nextA chooses a new A, a product of primes in the factor base close to sqrt(2n) / M,
+// and the first B for it.
+func (s *siqs) nextA() error {
+	target := new(big.Int).Lsh(s.n, 1)
+	target.Sqrt(target)
+	target.Div(target, big.NewInt(int64(s.radius)))
+	f, _ := new(big.Float).SetInt(target).Float64()
+	logTarget := math.Log(f)
+	poolLo := max(len(s.primes)/8, 1)
+	poolHi := max(len(s.primes)/3, poolLo+1)
+	pMid := float64(s.primes[(poolLo+poolHi)/2])
+	count := max(int(math.Round(logTarget/math.Log(pMid))), 1)
+	for tries := 0; ; tries++ {
+		if tries >= siqsMaxRepeatedA {
+			return ErrNoFactorFound
+		}
+		indices := []int{}
+		logRest := logTarget
+		for len(indices) < count-1 {
+			j := poolLo + s.rng.Intn(poolHi-poolLo)
+			if slices.Contains(indices, j) {
+				continue
+			}
+			indices = append(indices, j)
+			logRest -= math.Log(float64(s.primes[j]))
+		}
+		// choose the last prime so that A is as close to the target as possible
+		best := -1
+		for j := 1; j < len(s.primes); j++ {
+			if slices.Contains(indices, j) {
+				continue
+			}
+			if best < 0 || math.Abs(math.Log(float64(s.primes[j]))-logRest) < math.Abs(math.Log(float64(s.primes[best]))-logRest) {
+				best = j
+			}
+		}
+		indices = append(indices, best)
+		slices.Sort(indices)
+		key := make([]string, len(indices))
+		for i, j := range indices {
+			key[i] = strconv.Itoa(j)
+		}
+		if _, ok := s.usedA[strings.Join(key, ",")]; ok {
+			continue
+		}
+		s.usedA[strings.Join(key, ",")] = struct{}{}
+		s.initA(indices)
+		return nil
+	}
+}
+
+// initA sets A to the product of the primes at indices and initialises the first B.
+func (s *siqs) initA(indices []int) {
+	for _, j := range s.aIndices {
+		s.isA[j] = false
+	}
+	s.aIndices = indices
+	s.a = big.NewInt(1)
+	for _, j := range indices {
+		s.isA[j] = true
+		s.a.Mul(s.a, new(big.Int).SetUint64(s.primes[j]))
+	}
+	// B_l = (A / q_l) gamma_l with gamma_l = t_l (A / q_l)^-1 mod q_l, so that B = sum B_l satisfies B^2 = n mod A
+	s.bTerms = make([]*big.Int, len(indices))
+	s.bSigns = make([]int, len(indices))
+	s.b = new(big.Int)
+	for l, j := range indices {
+		q := s.primes[j]
+		bq := new(big.Int).SetUint64(q)
+		aq := new(big.Int).Div(s.a, bq)
+		inv := new(big.Int).ModInverse(new(big.Int).Mod(aq, bq), bq).Uint64()
+		gamma := s.roots[j] * inv % q
+		if gamma > q/2 {
+			gamma = q - gamma
+		}
+		s.bTerms[l] = aq.Mul(aq, new(big.Int).SetUint64(gamma))
+		s.bSigns[l] = 1
+		s.b.Add(s.b, s.bTerms[l])
+	}
+	s.bainv2 = make([][]uint64, len(indices))
+	for l := range s.bainv2 {
+		s.bainv2[l] = make([]uint64, len(s.primes))
+	}
+	r := new(big.Int)
+	for j := 1; j < len(s.primes); j++ {
+		if s.isA[j] {
+			continue
+		}
+		p := s.primes[j]
+		bp := new(big.Int).SetUint64(p)
+		s.ainv[j] = new(big.Int).ModInverse(r.Mod(s.a, bp), bp).Uint64()
+		for l, bl := range s.bTerms {
+			s.bainv2[l][j] = 2 * r.Mod(bl, bp).Uint64() % p * s.ainv[j] % p
+		}
+		bmod := r.Mod(s.b, bp).Uint64()
+		t := s.roots[j]
+		s.soln1[j] = s.ainv[j] * ((t + p - bmod) % p) % p
+		s.soln2[j] = s.ainv[j] * ((2*p - t - bmod) % p) % p
+	}
+}
+
+// nextB switches to the i-th B for the current A, for 0 < i < 2^(s-1), flipping the sign of one B_l as in a Gray code.
+func (s *siqs) nextB(i int) {
+	l := bits.TrailingZeros(uint(i))
+	delta := new(big.Int).Lsh(s.bTerms[l], 1)
+	if s.bSigns[l] > 0 {
+		s.b.Sub(s.b, delta)
+	} else {
+		s.b.Add(s.b, delta)
+	}
+	s.bSigns[l] = -s.bSigns[l]
+	for j := 1; j < len(s.primes); j++ {
+		if s.isA[j] {
+			continue
+		}
+		p := s.primes[j]
+		d := s.bainv2[l][j]
+		if s.bSigns[l] < 0 {
+			// B decreased by 2 B_l, so the roots increase by 2 B_l A^-1
+			s.soln1[j] = (s.soln1[j] + d) % p
+			s.soln2[j] = (s.soln2[j] + d) % p
+		} else {
+			s.soln1[j] = (s.soln1[j] + p - d) % p
+			s.soln2[j] = (s.soln2[j] + p - d) % p
+		}
+	}
+}
+
+// sieve sieves the current polynomial over [-M, M) and collects the relations found.
+func (s *siqs) sieve() {
+	buf := s.sieveBuf
+	clear(buf)
+	m := uint64(s.radius)
+	size := uint64(len(buf))
+	for j := 1; j < len(s.primes); j++ {
+		p := s.primes[j]
+		if p < siqsSmallPrime || s.isA[j] {
+			continue
+		}
+		lg := s.logs[j]
+		for i := (s.soln1[j] + m) % p; i < size; i += p {
+			buf[i] += lg
+		}
+		for i := (s.soln2[j] + m) % p; i < size; i += p {
+			buf[i] += lg
+		}
+	}
+	for i, v := range buf {
+		if v >= s.threshold {
+			s.check(int64(i) - int64(s.radius))
+		}
+	}
+}
+
+// check factors g(x) = ((A x + B)^2 - n) / A over the factor base and records a relation if it is smooth enough.
+func (s *siqs) check(x int64) {
+	y := new(big.Int).Mul(s.a, big.NewInt(x))
+	y.Add(y, s.b)
+	g := new(big.Int).Mul(y, y)
+	g.Sub(g, s.n)
+	g.Quo(g, s.a)
+	if g.Sign() == 0 {
+		return
+	}
+	cols := []int{}
+	if g.Sign() < 0 {
+		cols = append(cols, 0)
+		g.Neg(g)
+	}
+	// (A x + B)^2 - n = A g(x)
+	for _, j := range s.aIndices {
+		cols = append(cols, j+1)
+	}
+	for g.Bit(0) == 0 {
+		cols = append(cols, 1)
+		g.Rsh(g, 1)
+	}
+	q, r := new(big.Int), new(big.Int)
+	bp := new(big.Int)
+	for j := 1; j < len(s.primes); j++ {
+		p := s.primes[j]
+		if !s.isA[j] {
+			xm := uint64((x%int64(p) + int64(p)) % int64(p))
+			if xm != s.soln1[j] && xm != s.soln2[j] {
+				continue
+			}
+		}
+		bp.SetUint64(p)
+		for {
+			q.QuoRem(g, bp, r)
+			if r.Sign() != 0 {
+				break
+			}
+			g.Set(q)
+			cols = append(cols, j+1)
+		}
+	}
+	y.Mod(y, s.n)
+	if g.IsUint64() && g.Uint64() == 1 {
+		s.relations = append(s.relations, siqsRelation{y: y, cols: cols, large: big.NewInt(1)})
+		return
+	}
+	if !g.IsUint64() || g.Uint64() > s.lpBound {
+		return
+	}
+	large := g.Uint64()
+	other, ok := s.partials[large]
+	if !ok {
+		s.partials[large] = siqsRelation{y: y, cols: cols, large: g}
+		return
+	}
+	// two relations with the same large prime L combine into one with L^2
+	combined := new(big.Int).Mul(y, other.y)
+	combined.Mod(combined, s.n)
+	s.relations = append(s.relations, siqsRelation{
+		y:     combined,
+		cols:  append(cols, other.cols...),
+		large: g,
+	})
+	// each partial relation is used at most once
+	delete(s.partials, large)
+}
+
+// dependencies returns sets of relations whose product is a square modulo n, as indices of relations,
+// found by Gaussian elimination over GF(2).
+func (s *siqs) dependencies() [][]int {
+	ncols := len(s.primes) + 1
+	nrows := len(s.relations)
+	// each row holds the exponent vector modulo 2 followed by the set of relations it is the sum of
+	width := (ncols + nrows + 63) / 64
+	rows := make([][]uint64, nrows)
+	for i, rel := range s.relations {
+		row := make([]uint64, width)
+		for _, c := range rel.cols {
+			row[c/64] ^= 1 << (c % 64)
+		}
+		h := ncols + i
+		row[h/64] |= 1 << (h % 64)
+		rows[i] = row
+	}
+	pivoted := make([]bool, nrows)
+	for c := 0; c < ncols; c++ {
+		pivot := -1
+		for i := 0; i < nrows; i++ {
+			if !pivoted[i] && rows[i][c/64]>>(c%64)&1 == 1 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		pivoted[pivot] = true
+		for i := 0; i < nrows; i++ {
+			if i != pivot && rows[i][c/64]>>(c%64)&1 == 1 {
+				for w := range rows[i] {
+					rows[i][w] ^= rows[pivot][w]
+				}
+			}
+		}
+	}
+	deps := [][]int{}
+	for i := 0; i < nrows; i++ {
+		if pivoted[i] {
+			continue
+		}
+		dep := []int{}
+		for r := 0; r < nrows; r++ {
+			h := ncols + r
+			if rows[i][h/64]>>(h%64)&1 == 1 {
+				dep = append(dep, r)
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// factorFrom computes X = prod y and Y = sqrt(prod y^2) for the relations in dep,
+// and returns gcd(X - Y, n) if it is a nontrivial factor of n.
+func (s *siqs) factorFrom(dep []int) *big.Int {
+	x := big.NewInt(1)
+	y := big.NewInt(1)
+	exponents := make([]int, len(s.primes)+1)
+	for _, i := range dep {
+		rel := s.relations[i]
+		x.Mul(x, rel.y)
+		x.Mod(x, s.n)
+		y.Mul(y, rel.large)
+		y.Mod(y, s.n)
+		for _, c := range rel.cols {
+			exponents[c]++
+		}
+	}
+	for c := 1; c < len(exponents); c++ {
+		if exponents[c] == 0 {
+			continue
+		}
+		p := new(big.Int).SetUint64(s.primes[c-1])
+		y.Mul(y, p.Exp(p, big.NewInt(int64(exponents[c]/2)), s.n))
+		y.Mod(y, s.n)
+	}
+	g := new(big.Int).GCD(nil, nil, x.Sub(x, y).Abs(x), s.n)
+	if g.Cmp(big.NewInt(1)) == 0 || g.Cmp(s.n) == 0 {
+		return nil
+	}
+	return g
+}
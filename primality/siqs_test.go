@@ -0,0 +1,83 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// siqsTestInt returns a semiprime with two prime factors of about the given number of decimal digits each.
+func siqsTestInt(digits int) (*big.Int, *big.Int, *big.Int) {
+	base := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits-1)), nil)
+	p := nextPrime(new(big.Int).Add(base, big.NewInt(12345)))
+	q := nextPrime(new(big.Int).Mul(base, big.NewInt(7)))
+	return new(big.Int).Mul(p, q), p, q
+}
+
+func TestSIQS(t *testing.T) {
+	for _, digits := range []int{11, 16, 21} {
+		n, p, q := siqsTestInt(digits)
+		d, err := SIQS(context.Background(), n, nil)
+		assert.NoError(t, err)
+		assert.True(t, d.Cmp(p) == 0 || d.Cmp(q) == 0, "digits = %d", digits)
+	}
+}
+
+func TestSIQSTooSmall(t *testing.T) {
+	n, _, _ := siqsTestInt(8)
+	_, err := SIQS(context.Background(), n, nil)
+	assert.ErrorIs(t, err, ErrNoFactorFound)
+}
+
+func TestSIQSContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, _, _ := siqsTestInt(16)
+	_, err := SIQS(ctx, n, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFactorSIQS(t *testing.T) {
+	n, p, q := siqsTestInt(21)
+	opts := &FactorOptions{
+		RhoIterations: 1000,
+		ECM:           &ECMOptions{B1: 10, B2: 10, Curves: 1},
+	}
+	factors, err := FactorContext(context.Background(), n, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []FactorEntry{
+		{Prime: (*BigInt)(p), Exponent: 1},
+		{Prime: (*BigInt)(q), Exponent: 1},
+	}, factors)
+
+	opts.SIQS = &SIQSOptions{MaxDigits: 30}
+	_, err = FactorContext(context.Background(), n, opts)
+	assert.ErrorIs(t, err, ErrFactorizationFailed)
+}
+
+// N-1 = 2 k p q with p and q of about 21 digits.
+// Pollard's rho method and ECM are restricted so that only SIQS can split p q.
+func TestProveRegistrySIQS(t *testing.T) {
+	_, p, q := siqsTestInt(21)
+	n := new(big.Int)
+	for k := int64(1); ; k++ {
+		n.Mul(p, q)
+		n.Mul(n, big.NewInt(2*k))
+		n.Add(n, big.NewInt(1))
+		if n.ProbablyPrime(20) {
+			break
+		}
+	}
+	registry, err := ProveRegistry(n, &ProveOptions{
+		Factor: &FactorOptions{
+			RhoIterations: 1000,
+			ECM:           &ECMOptions{B1: 10, B2: 10, Curves: 1},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, registry) {
+		assert.NoError(t, registry.Check())
+	}
+}
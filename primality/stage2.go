@@ -0,0 +1,123 @@
+package primality
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Stage 2 of ECM, Pollard's p-1 method and Williams' p+1 method looks for a single prime s in (B1, B2]
+// that completes the group order. Each s is written as k D ± j with j in stage2BabySteps,
+// and a single comparison of the giant step k D with the baby step j covers both k D - j and k D + j.
+
+const (
+	// MaxB1 is the largest stage 1 bound ECM, PollardPMinus1 and WilliamsPPlus1 accept.
+	// Stage 1 sieves all primes up to B1 at once, so its memory grows linearly with B1.
+	MaxB1 = 1 << 28
+	// MaxB2 is the largest stage 2 bound ECM, PollardPMinus1 and WilliamsPPlus1 accept.
+	// The giant steps of stage 2 are kept for all primes up to B2, so their memory grows with B2.
+	MaxB2 = 1 << 36
+)
+
+// defaultB2 returns the default stage 2 bound for the stage 1 bound b1: 100 * b1, but at most MaxB2.
+func defaultB2(b1 uint64) uint64 {
+	if b1 > MaxB2/100 {
+		return MaxB2
+	}
+	return 100 * b1
+}
+
+// checkBounds returns an error wrapping ErrBoundTooLarge if b1 exceeds MaxB1 or b2 exceeds MaxB2.
+func checkBounds(b1, b2 uint64) error {
+	if b1 > MaxB1 {
+		return fmt.Errorf("%w: B1 = %d exceeds %d", ErrBoundTooLarge, b1, uint64(MaxB1))
+	}
+	if b2 > MaxB2 {
+		return fmt.Errorf("%w: B2 = %d exceeds %d", ErrBoundTooLarge, b2, uint64(MaxB2))
+	}
+	return nil
+}
+
+// stage2D is the giant step of stage 2.
+const stage2D = 2310
+
+// stage2Step lists the primes k D ± j in stage 2 for a giant step k, as indices of j in stage2BabySteps.
+type stage2Step struct {
+	k       uint64
+	indices []int
+}
+
+// stage2BabySteps are the odd numbers j < D/2 coprime to D.
+var stage2BabySteps = func() []uint64 {
+	js := []uint64{}
+	for j := uint64(1); j < stage2D/2; j += 2 {
+		if j%3 != 0 && j%5 != 0 && j%7 != 0 && j%11 != 0 {
+			js = append(js, j)
+		}
+	}
+	return js
+}()
+
+// stage2SegmentSteps is the number of giant steps whose primes stage2Steps sieves at once.
+const stage2SegmentSteps = 64
+
+// stage2Cache holds the results of stage2Steps by (b1, b2), as the same bounds are used for many curves and numbers.
+var stage2Cache = struct {
+	sync.Mutex
+	steps map[[2]uint64][]stage2Step
+}{steps: map[[2]uint64][]stage2Step{}}
+
+// stage2Steps returns the giant steps covering the primes in (b1, b2].
+// The result is shared between callers and must not be modified.
+func stage2Steps(b1, b2 uint64) []stage2Step {
+	stage2Cache.Lock()
+	defer stage2Cache.Unlock()
+	key := [2]uint64{b1, b2}
+	if steps, ok := stage2Cache.steps[key]; ok {
+		return steps
+	}
+	steps := computeStage2Steps(b1, b2)
+	stage2Cache.steps[key] = steps
+	return steps
+}
+
+// computeStage2Steps computes stage2Steps(b1, b2) with a segmented sieve,
+// so that its memory does not grow with b2.
+func computeStage2Steps(b1, b2 uint64) []stage2Step {
+	first := max(b1/stage2D, 1)
+	last := (b2 + stage2D/2) / stage2D
+	steps := []stage2Step{}
+	if first > last {
+		return steps
+	}
+	// every prime below the square root of the largest number sieved, k D + D/2 for k = last
+	basePrimes := primesBelow(uint64(math.Sqrt(float64(last*stage2D+stage2D/2))) + 2)
+	composite := make([]bool, stage2SegmentSteps*stage2D)
+	for k0 := first; k0 <= last; k0 += stage2SegmentSteps {
+		k1 := min(k0+stage2SegmentSteps-1, last)
+		// the segment [lo, lo+size) contains k D ± j for every k in [k0, k1]
+		lo := k0*stage2D - stage2D/2
+		size := (k1 - k0 + 1) * stage2D
+		clear(composite[:size])
+		for _, p := range basePrimes {
+			for m := max(p*p, (lo+p-1)/p*p); m < lo+size; m += p {
+				composite[m-lo] = true
+			}
+		}
+		isPrime := func(x uint64) bool {
+			return x > b1 && x <= b2 && !composite[x-lo]
+		}
+		for k := k0; k <= k1; k++ {
+			step := stage2Step{k: k}
+			for i, j := range stage2BabySteps {
+				if isPrime(k*stage2D-j) || isPrime(k*stage2D+j) {
+					step.indices = append(step.indices, i)
+				}
+			}
+			if len(step.indices) > 0 {
+				steps = append(steps, step)
+			}
+		}
+	}
+	return steps
+}
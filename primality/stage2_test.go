@@ -0,0 +1,35 @@
+package primality
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStage2Steps(t *testing.T) {
+	for _, bounds := range [][2]uint64{{1000, 5000}, {2000, 1_000_000}, {50_000, 50_100}, {5000, 1000}} {
+		b1, b2 := bounds[0], bounds[1]
+		// the steps found by sieving all of [0, b2] at once
+		isPrime := make([]bool, b2+1)
+		for _, p := range primesBelow(b2 + 1) {
+			isPrime[p] = p > b1
+		}
+		expected := []stage2Step{}
+		for k := max(b1/stage2D, 1); k*stage2D <= b2+stage2D/2; k++ {
+			step := stage2Step{k: k}
+			for i, j := range stage2BabySteps {
+				lo, hi := k*stage2D-j, k*stage2D+j
+				if (lo <= b2 && isPrime[lo]) || (hi <= b2 && isPrime[hi]) {
+					step.indices = append(step.indices, i)
+				}
+			}
+			if len(step.indices) > 0 {
+				expected = append(expected, step)
+			}
+		}
+		assert.Equal(t, expected, stage2Steps(b1, b2), "(%d, %d]", b1, b2)
+	}
+	// the steps are computed once per bounds
+	steps := stage2Steps(1000, 5000)
+	assert.Same(t, &steps[0], &stage2Steps(1000, 5000)[0])
+}
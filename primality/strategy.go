@@ -0,0 +1,353 @@
+package primality
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+var ErrUnknownObjective = errors.New("unknown objective")
+
+// Objective is what the prover minimizes when choosing among the proofs of a number.
+// The cost of a proof includes the estimated cost of proving its dependencies that are not known already.
+type Objective string
+
+const (
+	// ObjectiveDefault takes a Proth proof if there is one, and otherwise a generalized Pocklington proof.
+	// N-1 is only factored until the prime powers found make a valid A, which is then A;
+	// if N-1 is fully factored first, A is chosen from its largest prime factor.
+	ObjectiveDefault Objective = ""
+	// ObjectiveBytes minimizes the length of the JSON encoding of the proof.
+	ObjectiveBytes Objective = "bytes"
+	// ObjectiveDeps minimizes the number of primes the proof depends on.
+	ObjectiveDeps Objective = "deps"
+	// ObjectiveTime minimizes the estimated time to verify the proof.
+	ObjectiveTime Objective = "time"
+)
+
+// Objectives lists the valid objectives.
+var Objectives = []Objective{ObjectiveDefault, ObjectiveBytes, ObjectiveDeps, ObjectiveTime}
+
+func (o Objective) valid() bool {
+	return slices.Contains(Objectives, o)
+}
+
+// verificationCoster is implemented by proof methods that can estimate the time Check takes.
+type verificationCoster interface {
+	// verificationCost returns the estimated time to verify the proof of N, in arbitrary but common units.
+	verificationCost(N *big.Int) float64
+}
+
+// verificationCost counts a modular exponentiation by a k-bit exponent modulo an m-bit N as k m^2.
+func (p *GeneralizedPocklingtonProof) verificationCost(N *big.Int) float64 {
+	nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+	m := float64(N.BitLen())
+	cost := 0.0
+	for _, entry := range p.A.Factorization {
+		k := new(big.Int).Div(nMinus1, (*big.Int)(entry.Prime)).BitLen()
+		cost += float64(k) * m * m
+	}
+	return cost
+}
+
+// cost returns the cost of proof under the objective.
+func (o Objective) cost(proof *Proof) (float64, error) {
+	switch o {
+	case ObjectiveBytes:
+		encoded, err := proof.marshalContent()
+		if err != nil {
+			return 0, err
+		}
+		return float64(len(encoded)), nil
+	case ObjectiveDeps:
+		deps := map[string]struct{}{}
+		for _, d := range proof.Dep() {
+			deps[d.String()] = struct{}{}
+		}
+		return float64(len(deps)), nil
+	case ObjectiveTime:
+		if method, ok := proof.Method.(verificationCoster); ok {
+			return method.verificationCost((*big.Int)(proof.N)), nil
+		}
+		// without an estimate, assume a proof is as expensive as an exponentiation for N itself and one per dependency
+		m := float64(proof.N.bitLen())
+		return float64(len(proof.Dep())+1) * m * m * m, nil
+	}
+	return 0, nil
+}
+
+func (b *BigInt) bitLen() int {
+	return (*big.Int)(b).BitLen()
+}
+
+// maxExhaustiveFactors is the number of distinct prime factors of N-1 up to which
+// every subset of them is considered as the factorization of A.
+const maxExhaustiveFactors = 10
+
+// maxEvaluatedCandidates is the number of candidate proofs built in addition to the default one,
+// chosen by the cost of the proof estimated before the base is chosen.
+const maxEvaluatedCandidates = 4
+
+// maxLookaheadDepth is the number of levels of dependencies proven ahead to estimate the cost of a proof.
+const maxLookaheadDepth = 2
+
+// maxLookaheadBits is the size of the largest dependency proven ahead.
+// Larger ones take too long to prove just for an estimate, and their cost is estimated from their size.
+const maxLookaheadBits = 64
+
+// dependencyCoster estimates the cost of proofs under an objective, including their dependencies.
+type dependencyCoster struct {
+	ctx       context.Context
+	objective Objective
+	// known are the numbers proven elsewhere, which cost nothing
+	known map[string]struct{}
+	// memo maps a depth and a number to the estimated cost of proving the number
+	memo map[string]float64
+}
+
+func newDependencyCoster(ctx context.Context, objective Objective, known map[string]struct{}) *dependencyCoster {
+	return &dependencyCoster{
+		ctx:       ctx,
+		objective: objective,
+		known:     known,
+		memo:      map[string]float64{},
+	}
+}
+
+// totalCost returns the cost of proof plus the estimated cost of proving each of its dependencies
+// that is not known, looking ahead depth levels of dependencies.
+func (c *dependencyCoster) totalCost(proof *Proof, depth int) (float64, error) {
+	cost, err := c.objective.cost(proof)
+	if err != nil {
+		return 0, err
+	}
+	seen := map[string]struct{}{}
+	for _, d := range proof.Dep() {
+		if _, ok := c.known[d.String()]; ok {
+			continue
+		}
+		if _, ok := seen[d.String()]; ok {
+			continue
+		}
+		seen[d.String()] = struct{}{}
+		depCost, err := c.proofCost(d, depth)
+		if err != nil {
+			return 0, err
+		}
+		cost += depCost
+	}
+	return cost, nil
+}
+
+// proofCost returns the estimated cost of proving the prime n and its dependencies.
+// If depth > 0 and n has at most maxLookaheadBits bits, n is proven with ObjectiveDefault
+// and its dependencies are looked ahead depth-1 levels; otherwise the cost is that of placeholderProof(n).
+func (c *dependencyCoster) proofCost(n *big.Int, depth int) (float64, error) {
+	key := fmt.Sprintf("%d:%s", depth, n.String())
+	if cost, ok := c.memo[key]; ok {
+		return cost, nil
+	}
+	if depth > 0 && n.BitLen() <= maxLookaheadBits {
+		proof, err := prove(c.ctx, n, nil, nil, nil)
+		if err == nil {
+			cost, err := c.totalCost(proof, depth-1)
+			if err != nil {
+				return 0, err
+			}
+			c.memo[key] = cost
+			return cost, nil
+		}
+		if ctxErr := c.ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		// n could not be proven ahead, e.g. because n-1 could not be factored; estimate it from its size
+	}
+	cost, err := c.objective.cost(placeholderProof(n))
+	if err != nil {
+		return 0, err
+	}
+	c.memo[key] = cost
+	return cost, nil
+}
+
+// placeholderProof returns a proof of n of a typical size, which is not a valid proof:
+// a generalized Pocklington proof with A a single prime of half the size of n.
+func placeholderProof(n *big.Int) *Proof {
+	if n.Cmp(big.NewInt(2)) == 0 {
+		return &Proof{N: (*BigInt)(n)}
+	}
+	q := new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()/2+1))
+	return pocklingtonSkeleton(n, &FactoredInt{
+		Int:           (*BigInt)(q),
+		Factorization: []FactorEntry{{Prime: (*BigInt)(q), Exponent: 1}},
+	})
+}
+
+// proveBest returns the proof of the prime n > 2 that minimizes opts.Objective among the candidates:
+// the Proth proof, if any, and generalized Pocklington proofs with various A.
+// The cost of each candidate includes that of proving its dependencies not in known, as estimated by dependencyCoster.
+// The proof with the A chooseA picks from the full factorization of N-1 is always a candidate, and wins ties.
+func proveBest(ctx context.Context, n *big.Int, known map[string]struct{}, opts *ProveOptions, t *tracker) (*Proof, error) {
+	coster := newDependencyCoster(ctx, opts.objective(), known)
+	candidates := []*Proof{}
+	prothProof, err := proveProth(ctx, n)
+	if err == nil {
+		candidates = append(candidates, prothProof)
+	} else if err != ErrNotProth {
+		return nil, err
+	}
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	factors, unfactored, err := factorPartial(ctx, nMinus1, t.factorOptions(n, opts.factor()), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the A chooseA picks is tried first, unless a Proth proof was found
+	as := candidateAs(nMinus1, factors)
+	if defaultA, err := chooseA(nMinus1, factors, unfactored); err == nil {
+		as = slices.DeleteFunc(as, func(a *FactoredInt) bool {
+			return (*big.Int)(a.Int).Cmp((*big.Int)(defaultA.Int)) == 0
+		})
+		as = append([]*FactoredInt{defaultA}, as...)
+	} else if len(candidates) == 0 {
+		return nil, err
+	}
+	if len(as) > 0 {
+		// order the other candidates by the cost estimated with placeholder inverses, and keep the cheapest ones
+		type estimate struct {
+			a    *FactoredInt
+			cost float64
+		}
+		estimates := []estimate{}
+		for _, a := range as[1:] {
+			cost, err := coster.totalCost(pocklingtonSkeleton(n, a), maxLookaheadDepth)
+			if err != nil {
+				return nil, err
+			}
+			estimates = append(estimates, estimate{a: a, cost: cost})
+		}
+		slices.SortStableFunc(estimates, func(x, y estimate) int {
+			return compareCost(x.cost, y.cost)
+		})
+		selected := []*FactoredInt{as[0]}
+		for i := 0; i < len(estimates) && i < maxEvaluatedCandidates; i++ {
+			selected = append(selected, estimates[i].a)
+		}
+		t.proving(n)
+		for _, a := range selected {
+			proof, err := provePocklington(ctx, n, a)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, proof)
+		}
+	}
+	best, bestCost := candidates[0], 0.0
+	for i, proof := range candidates {
+		cost, err := coster.totalCost(proof, maxLookaheadDepth)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 || cost < bestCost {
+			best, bestCost = proof, cost
+		}
+	}
+	return best, nil
+}
+
+func compareCost(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// pocklingtonSkeleton returns a generalized Pocklington proof of n with the given A
+// and placeholders of the same size as N for the base and the inverses, which is not a valid proof.
+func pocklingtonSkeleton(n *big.Int, a *FactoredInt) *Proof {
+	invs := make([]Inverse, len(a.Factorization))
+	for i := range invs {
+		invs[i] = Inverse{Mod: (*BigInt)(n), Value: (*BigInt)(n), Inv: (*BigInt)(n)}
+	}
+	return &Proof{
+		N: (*BigInt)(n),
+		Method: &GeneralizedPocklingtonProof{
+			A:        a,
+			Base:     (*BigInt)(big.NewInt(2)),
+			Inverses: invs,
+		},
+	}
+}
+
+// candidateAs returns the valid choices of A for n = N-1 from its prime factors, i.e., products of some of their powers in n
+// with A > n/A and gcd(A, n/A) = 1. If n has at most maxExhaustiveFactors distinct prime factors,
+// every valid choice is returned; otherwise the product of all of them,
+// and the products of the largest and of the smallest prime powers that are just enough.
+func candidateAs(n *big.Int, factors []FactorEntry) []*FactoredInt {
+	powers := make([]*big.Int, len(factors))
+	for i, entry := range factors {
+		powers[i] = new(big.Int).Exp((*big.Int)(entry.Prime), big.NewInt(int64(entry.Exponent)), nil)
+	}
+	as := []*FactoredInt{}
+	seen := map[string]struct{}{}
+	// add adds the product of the prime powers at indices if it is a valid A
+	add := func(indices []int) {
+		a := big.NewInt(1)
+		entries := []FactorEntry{}
+		for _, i := range indices {
+			a.Mul(a, powers[i])
+			entries = append(entries, factors[i])
+		}
+		b := new(big.Int).Div(n, a)
+		if a.Cmp(b) <= 0 || new(big.Int).GCD(nil, nil, a, b).Cmp(big.NewInt(1)) != 0 {
+			return
+		}
+		if _, ok := seen[a.String()]; ok {
+			return
+		}
+		seen[a.String()] = struct{}{}
+		slices.SortFunc(entries, func(x, y FactorEntry) int {
+			return (*big.Int)(x.Prime).Cmp((*big.Int)(y.Prime))
+		})
+		as = append(as, &FactoredInt{Int: (*BigInt)(a), Factorization: entries})
+	}
+	if len(factors) <= maxExhaustiveFactors {
+		for mask := 1; mask < 1<<len(factors); mask++ {
+			indices := []int{}
+			for i := range factors {
+				if mask>>i&1 == 1 {
+					indices = append(indices, i)
+				}
+			}
+			add(indices)
+		}
+		return as
+	}
+	all := make([]int, len(factors))
+	for i := range all {
+		all[i] = i
+	}
+	add(all)
+	bySize := slices.Clone(all)
+	slices.SortStableFunc(bySize, func(i, j int) int {
+		return powers[i].Cmp(powers[j])
+	})
+	descending := slices.Clone(bySize)
+	slices.Reverse(descending)
+	// the largest prime powers, then the smallest ones, until A > n/A
+	for _, order := range [][]int{descending, bySize} {
+		a := big.NewInt(1)
+		for k, i := range order {
+			a.Mul(a, powers[i])
+			if new(big.Int).Mul(a, a).Cmp(n) > 0 {
+				add(order[:k+1])
+				break
+			}
+		}
+	}
+	return as
+}
@@ -0,0 +1,112 @@
+package primality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProveObjective(t *testing.T) {
+	ns := []*big.Int{}
+	for i := int64(1000); i < 1100; i++ {
+		if big.NewInt(i).ProbablyPrime(20) {
+			ns = append(ns, big.NewInt(i))
+		}
+	}
+	for _, s := range []string{"3221225473", "1000000007", "221360928884514619393"} {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			t.Fatalf("failed to parse %s", s)
+		}
+		ns = append(ns, n)
+	}
+	for _, n := range ns {
+		legacy, err := prove(context.Background(), n, nil, nil, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, objective := range []Objective{ObjectiveBytes, ObjectiveDeps, ObjectiveTime} {
+			proof, err := prove(context.Background(), n, nil, &ProveOptions{Objective: objective}, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, proof.Check(), "%s %s", n, objective)
+			coster := newDependencyCoster(context.Background(), objective, nil)
+			cost, err := coster.totalCost(proof, maxLookaheadDepth)
+			assert.NoError(t, err)
+			legacyCost, err := coster.totalCost(legacy, maxLookaheadDepth)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, cost, legacyCost, "%s %s", n, objective)
+		}
+	}
+}
+
+func TestProveObjectiveDeps(t *testing.T) {
+	// 17011 - 1 = 2 * 3^5 * 5 * 7: the default A is 2 * 3^5 * 5, but A = 3^5 > 70 suffices
+	n := big.NewInt(17011)
+	legacy, err := prove(context.Background(), n, nil, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, legacy.Dep(), 3)
+	proof, err := prove(context.Background(), n, nil, &ProveOptions{Objective: ObjectiveDeps}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, proof.Check())
+	assert.Equal(t, []*big.Int{big.NewInt(3)}, proof.Dep())
+}
+
+func TestDependencyCost(t *testing.T) {
+	proof, err := Prove(big.NewInt(17011))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, objective := range []Objective{ObjectiveBytes, ObjectiveDeps, ObjectiveTime} {
+		own, err := objective.cost(proof)
+		if !assert.NoError(t, err) {
+			return
+		}
+		total, err := newDependencyCoster(context.Background(), objective, nil).totalCost(proof, maxLookaheadDepth)
+		assert.NoError(t, err)
+		assert.Greater(t, total, own, objective)
+		// proofs of known numbers are not made again, so they cost nothing
+		known := map[string]struct{}{}
+		for _, d := range proof.Dep() {
+			known[d.String()] = struct{}{}
+		}
+		total, err = newDependencyCoster(context.Background(), objective, known).totalCost(proof, maxLookaheadDepth)
+		assert.NoError(t, err)
+		assert.Equal(t, own, total, objective)
+		// a dependency too large to prove ahead is estimated from its size
+		large := nextPrime(new(big.Int).Lsh(big.NewInt(1), maxLookaheadBits+10))
+		cost, err := newDependencyCoster(context.Background(), objective, nil).proofCost(large, maxLookaheadDepth)
+		assert.NoError(t, err)
+		estimate, err := objective.cost(placeholderProof(large))
+		assert.NoError(t, err)
+		assert.Equal(t, estimate, cost, objective)
+	}
+
+	// a proof without an estimate of its own still takes time to verify
+	cost, err := ObjectiveTime.cost(&Proof{N: (*BigInt)(big.NewInt(7))})
+	assert.NoError(t, err)
+	assert.Greater(t, cost, 0.0)
+}
+
+func TestProveRegistryObjective(t *testing.T) {
+	n, ok := new(big.Int).SetString("221360928884514619393", 10)
+	if !ok {
+		t.Fatal("failed to parse")
+	}
+	for _, objective := range Objectives {
+		registry, err := ProveRegistry(n, &ProveOptions{Objective: objective})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, registry.Check())
+	}
+	_, err := ProveRegistry(n, &ProveOptions{Objective: "size"})
+	assert.ErrorIs(t, err, ErrUnknownObjective)
+}
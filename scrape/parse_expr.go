@@ -4,76 +4,32 @@ import (
 	"errors"
 	"math/big"
 
+	"github.com/koba-e964/crypto-primality-proof/internal/expr"
 	"github.com/koba-e964/crypto-primality-proof/primality"
 )
 
-// Grammar:
-// <expr> ::= <pow> | <pow> " * " <expr>
-// <pow> ::= <num> | <num> "^" <num>
-// <num> = [0-9]+
-// This grammar is LL(1) and can be parsed by a recursive descent parser.
-
+// ParseExpr parses a factorization written as a product of powers like "2^4 * 3 * 5",
+// in the grammar of package expr.
 func ParseExpr(s string) (*primality.FactoredInt, error) {
-	_, result, err := parseExpr(s)
-	return result, err
-}
-
-func parseExpr(s string) (int, *primality.FactoredInt, error) {
-	// <expr> ::= <pow> | <pow> " * " <expr>
-	i, value, pow, err := parsePow(s)
+	e, err := expr.Parse(s)
 	if err != nil {
-		return i, nil, err
+		return nil, ErrNotANumber
 	}
-	if i+3 <= len(s) && s[i:i+3] == " * " {
-		i += 3
-		j, expr, err := parseExpr(s[i:])
-		if err != nil {
-			return i + j, nil, err
-		}
-		return i + j, &primality.FactoredInt{
-			Int:           (*primality.BigInt)(new(big.Int).Mul(value, (*big.Int)(expr.Int))),
-			Factorization: append([]primality.FactorEntry{pow}, expr.Factorization...),
-		}, nil
+	if len(e) != 1 {
+		return nil, errors.New("not a product")
 	}
-	return i, &primality.FactoredInt{
-		Int:           (*primality.BigInt)(value),
-		Factorization: []primality.FactorEntry{pow},
-	}, nil
-}
-
-func parsePow(s string) (int, *big.Int, primality.FactorEntry, error) {
-	// <pow> ::= <num> | <num> "^" <num>
-	i, value, err := parseNum(s)
-	if err != nil {
-		return i, nil, primality.FactorEntry{}, err
+	result := &primality.FactoredInt{
+		Int:           (*primality.BigInt)(e.Value()),
+		Factorization: []primality.FactorEntry{},
 	}
-	if i+1 < len(s) && s[i] == '^' {
-		i++
-		j, exp, err := parseNum(s[i:])
-		if err != nil {
-			return i + j, nil, primality.FactorEntry{}, err
+	for _, pow := range e[0].Powers {
+		if !pow.Exponent.IsInt64() {
+			return nil, errors.New("exponent is not an int64")
 		}
-		if !exp.IsInt64() {
-			return i + j, nil, primality.FactorEntry{}, errors.New("exponent is not an int64")
-		}
-		power := new(big.Int).Exp(value, exp, nil)
-		return i + j, power, primality.FactorEntry{Prime: (*primality.BigInt)(value), Exponent: int(exp.Int64())}, nil
-	}
-	return i, value, primality.FactorEntry{Prime: (*primality.BigInt)(value), Exponent: 1}, nil
-}
-
-func parseNum(s string) (int, *big.Int, error) {
-	// <num> = [0-9]+
-	i := 0
-	for i < len(s) && '0' <= s[i] && s[i] <= '9' {
-		i++
-	}
-	if i == 0 {
-		return 0, nil, ErrNotANumber
-	}
-	value, ok := new(big.Int).SetString(s[:i], 10)
-	if !ok {
-		return i, nil, ErrNotANumber
+		result.Factorization = append(result.Factorization, primality.FactorEntry{
+			Prime:    (*primality.BigInt)(new(big.Int).Set(pow.Base)),
+			Exponent: int(pow.Exponent.Int64()),
+		})
 	}
-	return i, value, nil
+	return result, nil
 }
@@ -1,12 +1,14 @@
 package scrape
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"math/big"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/koba-e964/crypto-primality-proof/primality"
@@ -26,6 +28,8 @@ type RawPrimeProofs struct {
 	CurveName string
 	Numbers   []string
 	Subpages  []RawProofPage
+	// Source is where the prime proofs page was read from. It is nil if the page was not fetched by this package.
+	Source *RawSource
 }
 
 type RawProofPage struct {
@@ -33,6 +37,47 @@ type RawProofPage struct {
 	AExpr    string
 	B        string
 	Inverses [][2]string
+	// Source is where the page was read from. It is nil if the page was not fetched by this package.
+	Source *RawSource
+}
+
+// RawSource records where and when a page was fetched.
+type RawSource struct {
+	URL       string
+	FetchedAt time.Time
+	// Hash is the hash of the raw page, as returned by primality.HashSource.
+	Hash string
+}
+
+// fetch reads the whole content of url.
+func fetch(url string) ([]byte, *RawSource, error) {
+	fetchedAt := time.Now().UTC()
+	reader, err := GetContent(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, &RawSource{
+		URL:       url,
+		FetchedAt: fetchedAt,
+		Hash:      primality.HashSource(content),
+	}, nil
+}
+
+func (s *RawSource) metadata() *primality.Metadata {
+	if s == nil {
+		return nil
+	}
+	fetchedAt := s.FetchedAt
+	return &primality.Metadata{
+		SourceURL:  s.URL,
+		FetchedAt:  &fetchedAt,
+		SourceHash: s.Hash,
+	}
 }
 
 func GetContent(url string) (io.ReadCloser, error) {
@@ -46,31 +91,27 @@ func GetContent(url string) (io.ReadCloser, error) {
 // ReadPrimeProofsPage reads the prime proofs page and returns proofs for the specified curve.
 // urlBase should be like "https://safecurves.cr.yp.to"
 func ReadPrimeProofsPage(urlBase string, curveName string) (*RawPrimeProofs, error) {
-	reader, err := GetContent(urlBase + "/primeproofs.html")
+	content, source, err := fetch(urlBase + "/primeproofs.html")
 	if err != nil {
 		return nil, err
 	}
-	defer reader.Close()
-	proofs, err := ParsePrimeProofsPage(reader, curveName)
+	proofs, err := ParsePrimeProofsPage(bytes.NewReader(content), curveName)
 	if err != nil {
 		return nil, err
 	}
+	proofs.Source = source
 	// read subpages
 	for _, number := range proofs.Numbers {
 		subUrl := urlBase + "/proof/" + number + ".html"
-		subReader, err := GetContent(subUrl)
+		subContent, subSource, err := fetch(subUrl)
 		if err != nil {
 			return nil, err
 		}
-		buf := new(strings.Builder)
-		if _, err := io.Copy(buf, subReader); err != nil {
-			return nil, err
-		}
-		subReader.Close()
-		subProof, err := ParseRawProofPage(buf.String())
+		subProof, err := ParseRawProofPage(string(subContent))
 		if err != nil {
 			return nil, err
 		}
+		subProof.Source = subSource
 		proofs.Subpages = append(proofs.Subpages, *subProof)
 	}
 
@@ -110,13 +151,19 @@ func ParsePrimeProofsPage(reader io.Reader, curveName string) (*RawPrimeProofs,
 		}
 	})
 	return &RawPrimeProofs{
-		Numbers: numbers,
+		CurveName: curveName,
+		Numbers:   numbers,
 	}, nil
 }
 
 func (r *RawPrimeProofs) Translate() (*primality.Registry, error) {
 	reg := primality.Registry{
-		Proofs: make([]primality.Proof, 0, len(r.Numbers)),
+		Proofs:   make([]primality.Proof, 0, len(r.Numbers)),
+		Metadata: r.Source.metadata(),
+	}
+	if reg.Metadata != nil {
+		reg.Metadata.CurveName = r.CurveName
+		reg.Metadata.Generator = primality.GeneratorVersion()
 	}
 	for _, subpage := range r.Subpages {
 		proof, err := subpage.Translate()
@@ -152,6 +199,7 @@ func ParseRawProofPage(s string) (*RawProofPage, error) {
 
 func (r *RawProofPage) Translate() (*primality.Proof, error) {
 	var p primality.Proof
+	p.Metadata = r.Source.metadata()
 	if r.N == "2" {
 		p.N = (*primality.BigInt)(big.NewInt(2))
 		return &p, nil
@@ -189,6 +237,6 @@ func (r *RawProofPage) Translate() (*primality.Proof, error) {
 		}
 	}
 	innerProof.Inverses = inverses
-	p.GeneralizedPocklington = &innerProof
+	p.Method = &innerProof
 	return &p, nil
 }
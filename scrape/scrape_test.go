@@ -4,7 +4,9 @@ import (
 	"math/big"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/koba-e964/crypto-primality-proof/primality"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -118,3 +120,35 @@ func TestParseRawProofPage2(t *testing.T) {
 		}
 	}
 }
+
+func TestTranslateMetadata(t *testing.T) {
+	fetchedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	raw := RawPrimeProofs{
+		CurveName: "TestCurve",
+		Numbers:   []string{"2"},
+		Subpages: []RawProofPage{
+			{
+				N: "2",
+				Source: &RawSource{
+					URL:       "https://safecurves.cr.yp.to/proof/2.html",
+					FetchedAt: fetchedAt,
+					Hash:      primality.HashSource([]byte("2 is prime.")),
+				},
+			},
+		},
+		Source: &RawSource{
+			URL:       "https://safecurves.cr.yp.to/primeproofs.html",
+			FetchedAt: fetchedAt,
+			Hash:      primality.HashSource([]byte("page")),
+		},
+	}
+	reg, err := raw.Translate()
+	if assert.NoError(t, err) {
+		assert.NoError(t, reg.Check())
+		assert.Equal(t, "TestCurve", reg.Metadata.CurveName)
+		assert.Equal(t, "https://safecurves.cr.yp.to/primeproofs.html", reg.Metadata.SourceURL)
+		assert.Equal(t, primality.GeneratorVersion(), reg.Metadata.Generator)
+		assert.Equal(t, "https://safecurves.cr.yp.to/proof/2.html", reg.Proofs[0].Metadata.SourceURL)
+		assert.Equal(t, &fetchedAt, reg.Proofs[0].Metadata.FetchedAt)
+	}
+}